@@ -131,6 +131,210 @@ var (
 			"type": "function"
 		}
 	]`)
+
+	// EIP-1577
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/IContentHashResolver.sol
+	// contenthash(bytes32)
+	IContentHashResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				}
+			],
+			"name": "contenthash",
+			"outputs": [
+				{
+					"internalType": "bytes",
+					"name": "",
+					"type": "bytes"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-181
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/INameResolver.sol
+	// name(bytes32)
+	INameResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				}
+			],
+			"name": "name",
+			"outputs": [
+				{
+					"internalType": "string",
+					"name": "",
+					"type": "string"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// https://github.com/ensdomains/ens-contracts/blob/master/contracts/resolvers/IMulticallable.sol
+	// multicall(bytes[])
+	IMulticallable = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes[]",
+					"name": "data",
+					"type": "bytes[]"
+				}
+			],
+			"name": "multicall",
+			"outputs": [
+				{
+					"internalType": "bytes[]",
+					"name": "results",
+					"type": "bytes[]"
+				}
+			],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-619
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/IPubkeyResolver.sol
+	// pubkey(bytes32)
+	IPubkeyResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				}
+			],
+			"name": "pubkey",
+			"outputs": [
+				{
+					"internalType": "bytes32",
+					"name": "x",
+					"type": "bytes32"
+				},
+				{
+					"internalType": "bytes32",
+					"name": "y",
+					"type": "bytes32"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-205
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/IABIResolver.sol
+	// ABI(bytes32,uint256)
+	IABIResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				},
+				{
+					"internalType": "uint256",
+					"name": "contentTypes",
+					"type": "uint256"
+				}
+			],
+			"name": "ABI",
+			"outputs": [
+				{
+					"internalType": "uint256",
+					"name": "",
+					"type": "uint256"
+				},
+				{
+					"internalType": "bytes",
+					"name": "",
+					"type": "bytes"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-165
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/IInterfaceResolver.sol
+	// interfaceImplementer(bytes32,bytes4)
+	IInterfaceResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				},
+				{
+					"internalType": "bytes4",
+					"name": "interfaceID",
+					"type": "bytes4"
+				}
+			],
+			"name": "interfaceImplementer",
+			"outputs": [
+				{
+					"internalType": "address",
+					"name": "",
+					"type": "address"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-1185
+	// https://github.com/ensdomains/ens-contracts/blob/v0.0.8/contracts/resolvers/profiles/IDNSRecordResolver.sol
+	// dnsRecord(bytes32,bytes32,uint16)
+	IDNSRecordResolver = mustParseABI(`[
+		{
+			"inputs": [
+				{
+					"internalType": "bytes32",
+					"name": "node",
+					"type": "bytes32"
+				},
+				{
+					"internalType": "bytes32",
+					"name": "name",
+					"type": "bytes32"
+				},
+				{
+					"internalType": "uint16",
+					"name": "resource",
+					"type": "uint16"
+				}
+			],
+			"name": "dnsRecord",
+			"outputs": [
+				{
+					"internalType": "bytes",
+					"name": "",
+					"type": "bytes"
+				}
+			],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
 )
 
 var (
@@ -138,6 +342,13 @@ var (
 	SelectorAddr          = mustGetSelector(IAddrResolver, "addr")
 	SelectorMulticoinAddr = mustGetSelector(IMulticoinAddrResolver, "addr")
 	SelectorText          = mustGetSelector(ITextResolver, "text")
+	SelectorContenthash   = mustGetSelector(IContentHashResolver, "contenthash")
+	SelectorName          = mustGetSelector(INameResolver, "name")
+	SelectorMulticall     = mustGetSelector(IMulticallable, "multicall")
+	SelectorPubkey        = mustGetSelector(IPubkeyResolver, "pubkey")
+	SelectorABI           = mustGetSelector(IABIResolver, "ABI")
+	SelectorInterface     = mustGetSelector(IInterfaceResolver, "interfaceImplementer")
+	SelectorDNSRecord     = mustGetSelector(IDNSRecordResolver, "dnsRecord")
 )
 
 func mustParseABI(json string) *ethabi.ABI {
@@ -0,0 +1,107 @@
+package coder
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*ABILookup)(nil)
+
+type ABILookup struct {
+	LookupRequest
+	contentTypes *big.Int
+}
+
+func NewABILookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*ABILookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IABIResolver.Methods["ABI"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	contentTypes, ok := decoded[1].(*big.Int) // uint256
+	if !ok {
+		return nil, malformedABIError(`failed to decode "contentTypes" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	return &ABILookup{newLookupRequest(name, node, abi.IABIResolver.Methods["ABI"], senderAddress, requestData), contentTypes}, nil
+}
+
+func (l *ABILookup) Kind() Kind {
+	return KindABI
+}
+
+// ContentTypes returns the bitmask of the content types accepted by the caller
+// (1: JSON, 2: zlib-compressed JSON, 4: CBOR, 8: URI)
+func (l *ABILookup) ContentTypes() *big.Int {
+	bi := new(big.Int)
+	return bi.Add(l.contentTypes, bi)
+}
+
+// EncodeResult takes the ABI-encoded (uint256,bytes) tuple of the content type
+// and the ABI data
+func (l *ABILookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	decoded, err := abi.IABIResolver.Methods["ABI"].Outputs.Unpack(result)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "result must be an ABI-encoded (uint256,bytes)")
+	}
+
+	contentType, ok := decoded[0].(*big.Int) // uint256
+	if !ok {
+		return nil, nil, errors.New(`failed to decode "contentType" in the result`)
+	}
+
+	data, ok := decoded[1].([]byte) // bytes
+	if !ok {
+		return nil, nil, errors.New(`failed to decode "data" in the result`)
+	}
+
+	return l.EncodeABI(contentType, data, expires)
+}
+
+// EncodeABI encodes the ABI data of the given content type. A zero content type
+// with empty data indicates that no ABI of the requested types was found.
+func (l *ABILookup) EncodeABI(contentType *big.Int, data []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if contentType.Sign() == 0 {
+		if len(data) != 0 {
+			return nil, nil, errors.New("data must be empty when content type is zero")
+		}
+	} else if !isPowerOfTwo(contentType) {
+		return nil, nil, errors.New("content type must be a single bit")
+	} else if new(big.Int).And(contentType, l.contentTypes).Sign() == 0 {
+		return nil, nil, errors.New("content type was not requested")
+	}
+
+	if encodedResult, err = abi.IABIResolver.Methods["ABI"].Outputs.Pack(
+		contentType, // uint256
+		data,        // bytes
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
+
+func isPowerOfTwo(n *big.Int) bool {
+	return n.Sign() > 0 && new(big.Int).And(n, new(big.Int).Sub(n, big.NewInt(1))).Sign() == 0
+}
@@ -0,0 +1,100 @@
+package coder
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareABILookup(t *testing.T, contentTypes *big.Int) (senderAddress common.Address, requestData []byte, lookup *ABILookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	abiInputs, err := abi.IABIResolver.Methods["ABI"].Inputs.Pack(node, contentTypes)
+	require.Nil(t, err)
+
+	abiCallData := make([]byte, len(abiInputs)+4)
+	copy(abiCallData, abi.IABIResolver.Methods["ABI"].ID)
+	copy(abiCallData[4:], abiInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, abiCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewABILookup(name, abiInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, contentTypes, lookup.ContentTypes())
+
+	return *sender, requestData, lookup
+}
+
+func TestABILookupEncodeResult(t *testing.T) {
+	sender, requestData, lookup := prepareABILookup(t, big.NewInt(1|8))
+
+	data := []byte(`[{"type":"function","name":"foo","inputs":[],"outputs":[]}]`)
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeABI(big.NewInt(1), data, expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.IABIResolver.Methods["ABI"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	require.Equal(t, big.NewInt(1), decoded[0])
+	require.Equal(t, data, decoded[1])
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+
+	// EncodeResult accepts the ABI-encoded tuple
+	reencoded, rehash, err := lookup.EncodeResult(resultData, expires)
+	require.Nil(t, err)
+	require.Equal(t, resultData, reencoded)
+	require.Equal(t, hash, rehash)
+
+	// not found
+	resultData, _, err = lookup.EncodeABI(big.NewInt(0), []byte{}, expires)
+	require.Nil(t, err)
+
+	decoded, err = abi.IABIResolver.Methods["ABI"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+	require.Zero(t, decoded[0].(*big.Int).Sign())
+	require.Empty(t, decoded[1])
+}
+
+func TestABILookupEncodeResultInvalidContentType(t *testing.T) {
+	_, _, lookup := prepareABILookup(t, big.NewInt(1|8))
+
+	expires := makeExpires()
+	data := []byte("https://example.com/abi.json")
+
+	for _, tc := range []struct {
+		contentType *big.Int
+		data        []byte
+		err         string
+	}{
+		{big.NewInt(2), data, "content type was not requested"},
+		{big.NewInt(9), data, "content type must be a single bit"},
+		{big.NewInt(0), data, "data must be empty when content type is zero"},
+	} {
+		resultData, hash, err := lookup.EncodeABI(tc.contentType, tc.data, expires)
+		require.Nil(t, resultData)
+		require.Nil(t, hash)
+		require.EqualError(t, err, tc.err)
+	}
+}
@@ -12,36 +12,34 @@ import (
 var _ Lookup = (*AddrLookup)(nil)
 
 type AddrLookup struct {
-	name          string
-	senderAddress common.Address
-	requestData   []byte
+	LookupRequest
 }
 
 func NewAddrLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*AddrLookup, error) {
 	nh, err := namehash.NameHash(name)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get namehash")
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
 	}
 
 	decoded, err := abi.IAddrResolver.Methods["addr"].Inputs.Unpack(lookupInputs)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode lookup inputs")
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
 	}
 
 	node, ok := decoded[0].([32]byte) // bytes32
 	if !ok {
-		return nil, errors.New(`failed to decode "node" in lookup inputs`)
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
 	}
 
 	if !bytes.Equal(node[:], nh[:]) {
-		return nil, errors.New("name hash does not match the lookup input")
+		return nil, ErrNodeMismatch
 	}
 
-	return &AddrLookup{name, senderAddress, requestData}, nil
+	return &AddrLookup{newLookupRequest(name, node, abi.IAddrResolver.Methods["addr"], senderAddress, requestData)}, nil
 }
 
-func (l *AddrLookup) Name() string {
-	return l.name
+func (l *AddrLookup) Kind() Kind {
+	return KindAddr
 }
 
 func (l *AddrLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
@@ -55,7 +53,7 @@ func (l *AddrLookup) EncodeResult(result []byte, expires uint64) (encodedResult
 		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
 	}
 
-	hash = hashResult(l.senderAddress, expires, l.requestData, encodedResult)
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
 
 	return encodedResult, hash, nil
 }
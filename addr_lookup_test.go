@@ -5,9 +5,9 @@ import (
 	"time"
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
 	"github.com/ethereum/go-ethereum/common"
-	dnsname "github.com/petejkim/ens-dnsname"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,7 +60,7 @@ func TestAddrLookupEncodeResult(t *testing.T) {
 
 	require.Equal(t, *resultAddress, decoded[0])
 
-	require.Equal(t, hashResult(sender, expires, requestData, resultData), hash)
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
 }
 
 func TestAddrLookupEncodeResultInvalidAddress(t *testing.T) {
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// lookupJSON is the JSON representation of a decoded lookup. Type is the name
+// of the resolver function being called.
+type lookupJSON struct {
+	Type           string        `json:"type"`
+	Name           string        `json:"name,omitempty"`
+	Node           string        `json:"node,omitempty"`
+	UnknownLabels  bool          `json:"unknownLabels,omitempty"`
+	CoinType       string        `json:"coinType,omitempty"`
+	ChainID        uint64        `json:"chainId,omitempty"`
+	Key            string        `json:"key,omitempty"`
+	ContentTypes   string        `json:"contentTypes,omitempty"`
+	InterfaceID    string        `json:"interfaceId,omitempty"`
+	DNSName        string        `json:"dnsName,omitempty"`
+	Resource       *uint16       `json:"resource,omitempty"`
+	ReverseAddress string        `json:"reverseAddress,omitempty"`
+	Calls          []*lookupJSON `json:"calls,omitempty"`
+	CallData       string        `json:"callData,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+func runDecode(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("decode", stderr)
+	sender := fs.String("sender", "", "address of the offchain resolver contract")
+	data := fs.String("data", "", "hex-encoded resolve(bytes,bytes) calldata")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "sender", "data"); err != nil {
+		return err
+	}
+
+	lookup, err := coder.DecodeRequest(*sender, *data)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the request")
+	}
+
+	return writeJSON(stdout, describeLookup(lookup))
+}
+
+func describeLookup(lookup coder.Lookup) *lookupJSON {
+	out := &lookupJSON{Name: lookup.Name()}
+	if l, ok := lookup.(coder.UnknownLabelsLookup); ok {
+		out.UnknownLabels = l.HasUnknownLabels()
+	}
+	if node := lookup.Node(); node != ([32]byte{}) {
+		out.Node = hexutil.Encode(node[:])
+	}
+
+	switch l := lookup.(type) {
+	case *coder.AddrLookup:
+		out.Type = "addr"
+		out.CoinType = "60"
+	case *coder.MulticoinAddrLookup:
+		out.Type = "addr"
+		out.CoinType = l.CoinType().String()
+		out.ChainID, _ = l.ChainID()
+	case *coder.TextLookup:
+		out.Type = "text"
+		out.Key = l.Key()
+	case *coder.ContenthashLookup:
+		out.Type = "contenthash"
+	case *coder.NameLookup:
+		out.Type = "name"
+		if l.IsReverse() {
+			out.ReverseAddress = hexutil.Encode(l.ReverseAddress())
+			out.CoinType = l.ReverseCoinType().String()
+		}
+	case *coder.PubkeyLookup:
+		out.Type = "pubkey"
+	case *coder.ABILookup:
+		out.Type = "ABI"
+		out.ContentTypes = l.ContentTypes().String()
+	case *coder.InterfaceLookup:
+		out.Type = "interfaceImplementer"
+		id := l.InterfaceID()
+		out.InterfaceID = hexutil.Encode(id[:])
+	case *coder.DNSRecordLookup:
+		out.Type = "dnsRecord"
+		dnsName := l.DNSName()
+		out.DNSName = hexutil.Encode(dnsName[:])
+		resource := l.Resource()
+		out.Resource = &resource
+	case *coder.MulticallLookup:
+		out.Type = "multicall"
+		for _, call := range l.Calls() {
+			if call.Err != nil {
+				out.Calls = append(out.Calls, &lookupJSON{
+					CallData: hexutil.Encode(call.CallData),
+					Error:    call.Err.Error(),
+				})
+				continue
+			}
+			callJSON := describeLookup(call.Lookup)
+			callJSON.CallData = hexutil.Encode(call.CallData)
+			out.Calls = append(out.Calls, callJSON)
+		}
+	default:
+		out.Type = "unknown"
+	}
+
+	return out
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+func runEncodeRequest(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("encode-request", stderr)
+	lookupType := fs.String("type", "addr", "resolver function: addr, text, contenthash, name, pubkey, ABI, interfaceImplementer or dnsRecord")
+	name := fs.String("name", "", "name to resolve")
+	coinType := fs.String("coin-type", "", "coin type for addr, addr(bytes32) is used if omitted")
+	key := fs.String("key", "", "key for text")
+	contentTypes := fs.String("content-types", "", "content types bitmask for ABI")
+	interfaceID := fs.String("interface-id", "", "hex-encoded 4-byte interface ID for interfaceImplementer")
+	dnsName := fs.String("dns-name", "", "hex-encoded keccak256 of the dns-encoded name for dnsRecord")
+	resource := fs.Uint("resource", 0, "resource record type for dnsRecord")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "name"); err != nil {
+		return err
+	}
+
+	var (
+		requestData []byte
+		err         error
+	)
+	switch *lookupType {
+	case "addr":
+		if *coinType == "" {
+			requestData, err = coder.EncodeAddrRequest(*name)
+			break
+		}
+		ct, ok := new(big.Int).SetString(*coinType, 10)
+		if !ok || ct.Sign() < 0 {
+			return errors.Errorf("invalid coin type %q", *coinType)
+		}
+		requestData, err = coder.EncodeMulticoinAddrRequest(*name, ct)
+
+	case "text":
+		if err := requireFlags(fs, "key"); err != nil {
+			return err
+		}
+		requestData, err = coder.EncodeTextRequest(*name, *key)
+
+	case "contenthash":
+		requestData, err = coder.EncodeContenthashRequest(*name)
+
+	case "name":
+		requestData, err = coder.EncodeNameRequest(*name)
+
+	case "pubkey":
+		requestData, err = coder.EncodePubkeyRequest(*name)
+
+	case "ABI":
+		if err := requireFlags(fs, "content-types"); err != nil {
+			return err
+		}
+		ct, ok := new(big.Int).SetString(*contentTypes, 0)
+		if !ok || ct.Sign() < 0 {
+			return errors.Errorf("invalid content types %q", *contentTypes)
+		}
+		requestData, err = coder.EncodeABIRequest(*name, ct)
+
+	case "interfaceImplementer":
+		if err := requireFlags(fs, "interface-id"); err != nil {
+			return err
+		}
+		b, err := decodeHex(*interfaceID)
+		if err != nil || len(b) != 4 {
+			return errors.New("interface ID must be 4 hex-encoded bytes")
+		}
+		var id [4]byte
+		copy(id[:], b)
+		if requestData, err = coder.EncodeInterfaceRequest(*name, id); err != nil {
+			return errors.Wrap(err, "failed to encode the request")
+		}
+
+	case "dnsRecord":
+		if err := requireFlags(fs, "dns-name", "resource"); err != nil {
+			return err
+		}
+		b, err := decodeHex(*dnsName)
+		if err != nil || len(b) != 32 {
+			return errors.New("DNS name must be 32 hex-encoded bytes")
+		}
+		if *resource > 0xffff {
+			return errors.New("resource must fit in 16 bits")
+		}
+		var node [32]byte
+		copy(node[:], b)
+		if requestData, err = coder.EncodeDNSRecordRequest(*name, node, uint16(*resource)); err != nil {
+			return errors.Wrap(err, "failed to encode the request")
+		}
+
+	default:
+		return errors.Errorf("unsupported type %q", *lookupType)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the request")
+	}
+
+	fmt.Fprintln(stdout, hexutil.Encode(requestData))
+	return nil
+}
+
+func runEncodeResponse(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("encode-response", stderr)
+	sender := fs.String("sender", "", "address of the offchain resolver contract")
+	data := fs.String("data", "", "hex-encoded resolve(bytes,bytes) calldata")
+	result := fs.String("result", "", "hex-encoded result, in the format expected by the EncodeResult method of the lookup")
+	resultText := fs.String("result-text", "", "result as a string, for text and name lookups, or the human-readable address for multicoin addr lookups")
+	expires := fs.Uint64("expires", 0, "expiry of the response as a unix timestamp")
+	keyFile := fs.String("keyfile", "", "file containing the hex-encoded private key of the signer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "sender", "data", "expires", "keyfile"); err != nil {
+		return err
+	}
+
+	lookup, err := coder.DecodeRequest(*sender, *data)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the request")
+	}
+
+	resultBytes := []byte(*resultText)
+	if *result != "" {
+		if *resultText != "" {
+			return errors.New("only one of -result and -result-text may be set")
+		}
+		if resultBytes, err = decodeHex(*result); err != nil {
+			return errors.New("result is not a valid hex string")
+		}
+	} else if l, ok := lookup.(*coder.MulticoinAddrLookup); ok && *resultText != "" {
+		if resultBytes, err = l.ParseAddress(*resultText); err != nil {
+			return errors.Wrap(err, "failed to parse the address")
+		}
+	}
+
+	signer, err := loadSigner(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	encodedResult, hash, err := lookup.EncodeResult(resultBytes, *expires)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the result")
+	}
+
+	signature, err := signer.Sign(hash)
+	if err != nil {
+		return err
+	}
+
+	responseData, err := coder.EncodeResponse(encodedResult, *expires, signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the response")
+	}
+
+	fmt.Fprintln(stdout, hexutil.Encode(responseData))
+	return nil
+}
+
+func loadSigner(path string) (*coder.PrivateKeySigner, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the key file")
+	}
+	return coder.NewPrivateKeySignerFromHex(strings.TrimSpace(string(keyData)))
+}
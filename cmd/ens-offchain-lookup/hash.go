@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+func runNamehash(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("namehash", stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: namehash <name>")
+	}
+
+	hash, err := namehash.NameHash(fs.Arg(0))
+	if err != nil {
+		return errors.Wrap(err, "failed to hash the name")
+	}
+
+	fmt.Fprintln(stdout, hexutil.Encode(hash[:]))
+	return nil
+}
+
+func runLabelhash(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("labelhash", stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: labelhash <label>")
+	}
+
+	hash, err := namehash.LabelHash(fs.Arg(0))
+	if err != nil {
+		return errors.Wrap(err, "failed to hash the label")
+	}
+
+	fmt.Fprintln(stdout, hexutil.Encode(hash[:]))
+	return nil
+}
@@ -0,0 +1,104 @@
+// Command ens-offchain-lookup decodes, encodes and signs the payloads of ENS
+// offchain lookups (EIP-3668 CCIP-Read requests to an offchain resolver
+// gateway) for debugging.
+//
+// Usage:
+//
+//	ens-offchain-lookup decode -sender 0x... -data 0x...
+//	ens-offchain-lookup encode-request -type text -name test.eth -key email
+//	ens-offchain-lookup encode-response -sender 0x... -data 0x... -result 0x... -expires 1700000000 -keyfile key.txt
+//	ens-offchain-lookup verify -sender 0x... -data 0x... -response 0x... [-signer 0x...]
+//	ens-offchain-lookup namehash test.eth
+//	ens-offchain-lookup labelhash test
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: ens-offchain-lookup <command> [flags]
+
+commands:
+  decode           decode a resolve(bytes,bytes) request and print the lookup as JSON
+  encode-request   encode a resolve(bytes,bytes) request for a lookup
+  encode-response  encode and sign the response data for a request
+  verify           decode a response and recover its signer
+  namehash         print the namehash of a name
+  labelhash        print the labelhash of a label
+
+Run "ens-offchain-lookup <command> -h" for the flags of a command.
+`
+
+type command func(args []string, stdout io.Writer, stderr io.Writer) error
+
+var commands = map[string]command{
+	"decode":          runDecode,
+	"encode-request":  runEncodeRequest,
+	"encode-response": runEncodeResponse,
+	"verify":          runVerify,
+	"namehash":        runNamehash,
+	"labelhash":       runLabelhash,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(stderr, usage)
+		return errors.Errorf("unknown command %q", args[0])
+	}
+
+	return cmd(args[1:], stdout, stderr)
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// requireFlags returns an error naming the first flag that was not set
+func requireFlags(fs *flag.FlagSet, names ...string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for _, name := range names {
+		if !set[name] {
+			return errors.Errorf("-%s is required", name)
+		}
+	}
+	return nil
+}
+
+func decodeHex(str string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(str, "0x"))
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testSender = "0x000000000000000000000000000000000000c0de"
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, &stdout, &stderr)
+	return strings.TrimSpace(stdout.String()), err
+}
+
+func TestNamehash(t *testing.T) {
+	out, err := runCommand(t, "namehash", "eth")
+	require.Nil(t, err)
+	require.Equal(t, "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae", out)
+
+	out, err = runCommand(t, "labelhash", "eth")
+	require.Nil(t, err)
+	require.Equal(t, "0x4f5b812789fc606be1b3b16908db13fc7a9adf7ca72641f84d75b47069d3d7f0", out)
+
+	_, err = runCommand(t, "namehash")
+	require.NotNil(t, err)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, err := runCommand(t, "resolve")
+	require.EqualError(t, err, `unknown command "resolve"`)
+}
+
+func TestDecode(t *testing.T) {
+	data, err := runCommand(t, "encode-request", "-type", "addr", "-name", "test.eth", "-coin-type", "0")
+	require.Nil(t, err)
+
+	out, err := runCommand(t, "decode", "-sender", testSender, "-data", data)
+	require.Nil(t, err)
+
+	var lookup lookupJSON
+	require.Nil(t, json.Unmarshal([]byte(out), &lookup))
+	require.Equal(t, "addr", lookup.Type)
+	require.Equal(t, "test.eth", lookup.Name)
+	require.Equal(t, "0xeb4f647bea6caa36333c816d7b46fdcb05f9466ecacc140ea8c66faf15b3d9f1", lookup.Node)
+	require.Equal(t, "0", lookup.CoinType)
+
+	data, err = runCommand(t, "encode-request", "-type", "text", "-name", "test.eth", "-key", "email")
+	require.Nil(t, err)
+
+	out, err = runCommand(t, "decode", "-sender", testSender, "-data", data)
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal([]byte(out), &lookup))
+	require.Equal(t, "text", lookup.Type)
+	require.Equal(t, "email", lookup.Key)
+
+	data, err = runCommand(t, "encode-request", "-type", "addr", "-name", "test.eth", "-coin-type", "2147492101")
+	require.Nil(t, err)
+
+	lookup = lookupJSON{}
+	out, err = runCommand(t, "decode", "-sender", testSender, "-data", data)
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal([]byte(out), &lookup))
+	require.Equal(t, "2147492101", lookup.CoinType)
+	require.Equal(t, uint64(8453), lookup.ChainID)
+
+	_, err = runCommand(t, "decode", "-sender", testSender)
+	require.EqualError(t, err, "-data is required")
+
+	_, err = runCommand(t, "decode", "-sender", testSender, "-data", "0x1234")
+	require.Contains(t, err.Error(), "failed to decode the request")
+}
+
+func TestEncodeResponseAndVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	require.Nil(t, os.WriteFile(keyFile, []byte(hexutil.Encode(crypto.FromECDSA(key))+"\n"), 0600))
+
+	data, err := runCommand(t, "encode-request", "-type", "text", "-name", "test.eth", "-key", "email")
+	require.Nil(t, err)
+
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	responseData, err := runCommand(t, "encode-response",
+		"-sender", testSender, "-data", data,
+		"-result-text", "test@example.com",
+		"-expires", expires, "-keyfile", keyFile,
+	)
+	require.Nil(t, err)
+
+	out, err := runCommand(t, "verify",
+		"-sender", testSender, "-data", data,
+		"-response", responseData, "-signer", address,
+	)
+	require.Nil(t, err)
+
+	var response responseJSON
+	require.Nil(t, json.Unmarshal([]byte(out), &response))
+	require.Equal(t, address, response.Signer)
+	require.Equal(t, expires, strconv.FormatUint(response.Expires, 10))
+
+	_, err = runCommand(t, "verify",
+		"-sender", testSender, "-data", data,
+		"-response", responseData, "-signer", testSender,
+	)
+	require.EqualError(t, err, "invalid signature")
+
+	_, err = runCommand(t, "encode-response",
+		"-sender", testSender, "-data", data,
+		"-result", "0x1234", "-result-text", "test@example.com",
+		"-expires", expires, "-keyfile", keyFile,
+	)
+	require.EqualError(t, err, "only one of -result and -result-text may be set")
+}
+
+func TestEncodeResponseAddress(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	require.Nil(t, os.WriteFile(keyFile, []byte(hexutil.Encode(crypto.FromECDSA(key))), 0600))
+
+	data, err := runCommand(t, "encode-request", "-type", "addr", "-name", "test.eth", "-coin-type", "0")
+	require.Nil(t, err)
+
+	expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	responseData, err := runCommand(t, "encode-response",
+		"-sender", testSender, "-data", data,
+		"-result-text", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+		"-expires", expires, "-keyfile", keyFile,
+	)
+	require.Nil(t, err)
+
+	out, err := runCommand(t, "verify",
+		"-sender", testSender, "-data", data,
+		"-response", responseData, "-signer", crypto.PubkeyToAddress(key.PublicKey).Hex(),
+	)
+	require.Nil(t, err)
+
+	var response responseJSON
+	require.Nil(t, json.Unmarshal([]byte(out), &response))
+	require.Contains(t, response.Result, "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+
+	_, err = runCommand(t, "encode-response",
+		"-sender", testSender, "-data", data,
+		"-result-text", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb",
+		"-expires", expires, "-keyfile", keyFile,
+	)
+	require.EqualError(t, err, "failed to parse the address: invalid address for coin type 0: invalid address checksum")
+}
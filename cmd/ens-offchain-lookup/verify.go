@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+type responseJSON struct {
+	Result    string `json:"result"`
+	Expires   uint64 `json:"expires"`
+	Signature string `json:"signature"`
+	Hash      string `json:"hash"`
+	Signer    string `json:"signer"`
+}
+
+func runVerify(args []string, stdout io.Writer, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	sender := fs.String("sender", "", "address of the offchain resolver contract")
+	data := fs.String("data", "", "hex-encoded resolve(bytes,bytes) calldata")
+	responseData := fs.String("response", "", "hex-encoded response data")
+	signers := fs.String("signer", "", "comma-separated addresses of the expected signers; if set, fails unless the response was signed by one of them and has not expired")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "sender", "data", "response"); err != nil {
+		return err
+	}
+
+	lookup, err := coder.DecodeRequest(*sender, *data)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the request")
+	}
+
+	responseBytes, err := decodeHex(*responseData)
+	if err != nil {
+		return errors.New("response is not a valid hex string")
+	}
+
+	response, err := coder.DecodeResponse(lookup, responseBytes)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSON(stdout, &responseJSON{
+		Result:    hexutil.Encode(response.Result),
+		Expires:   response.Expires,
+		Signature: hexutil.Encode(response.Signature),
+		Hash:      hexutil.Encode(response.Hash),
+		Signer:    response.Signer.Hex(),
+	}); err != nil {
+		return err
+	}
+
+	if *signers == "" {
+		return nil
+	}
+
+	var addresses []common.Address
+	for _, s := range strings.Split(*signers, ",") {
+		s = strings.TrimSpace(s)
+		if !common.IsHexAddress(s) {
+			return errors.Errorf("invalid signer address %q", s)
+		}
+		addresses = append(addresses, common.HexToAddress(s))
+	}
+
+	return coder.Verify(response, addresses, time.Now())
+}
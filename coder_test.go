@@ -5,14 +5,16 @@ import (
 	"fmt"
 	"math/big"
 	mathrand "math/rand"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	dnsname "github.com/petejkim/ens-dnsname"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -155,6 +157,282 @@ func TestDecodeRequestTextLookup(t *testing.T) {
 	require.Equal(t, resolveCallData, lookup.requestData)
 }
 
+func TestDecodeRequestContenthashLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	contenthashInputs, err := abi.IContentHashResolver.Methods["contenthash"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	contenthashCallData := make([]byte, len(contenthashInputs)+4)
+	copy(contenthashCallData, abi.IContentHashResolver.Methods["contenthash"].ID)
+	copy(contenthashCallData[4:], contenthashInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, contenthashCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*ContenthashLookup)
+	require.True(t, ok, "expected the decoded lookup to be a ContenthashLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestNameLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	reverseAddress, err := randomAddress()
+	require.Nil(t, err)
+
+	name := hexutil.Encode(reverseAddress.Bytes())[2:] + ".addr.reverse"
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	nameInputs, err := abi.INameResolver.Methods["name"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	nameCallData := make([]byte, len(nameInputs)+4)
+	copy(nameCallData, abi.INameResolver.Methods["name"].ID)
+	copy(nameCallData[4:], nameInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, nameCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*NameLookup)
+	require.True(t, ok, "expected the decoded lookup to be a NameLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, reverseAddress.Bytes(), lookup.ReverseAddress())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestMulticallLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	addrCallData := make([]byte, len(addrInputs)+4)
+	copy(addrCallData, abi.IAddrResolver.Methods["addr"].ID)
+	copy(addrCallData[4:], addrInputs)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack([][]byte{addrCallData})
+	require.Nil(t, err)
+
+	multicallCallData := make([]byte, len(multicallInputs)+4)
+	copy(multicallCallData, abi.IMulticallable.Methods["multicall"].ID)
+	copy(multicallCallData[4:], multicallInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, multicallCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*MulticallLookup)
+	require.True(t, ok, "expected the decoded lookup to be a MulticallLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Len(t, lookup.Calls(), 1)
+	require.IsType(t, &AddrLookup{}, lookup.Calls()[0].Lookup)
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestPubkeyLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	pubkeyInputs, err := abi.IPubkeyResolver.Methods["pubkey"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	pubkeyCallData := make([]byte, len(pubkeyInputs)+4)
+	copy(pubkeyCallData, abi.IPubkeyResolver.Methods["pubkey"].ID)
+	copy(pubkeyCallData[4:], pubkeyInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, pubkeyCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*PubkeyLookup)
+	require.True(t, ok, "expected the decoded lookup to be a PubkeyLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestABILookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	contentTypes := big.NewInt(1 | 2 | 4 | 8)
+
+	abiInputs, err := abi.IABIResolver.Methods["ABI"].Inputs.Pack(node, contentTypes)
+	require.Nil(t, err)
+
+	abiCallData := make([]byte, len(abiInputs)+4)
+	copy(abiCallData, abi.IABIResolver.Methods["ABI"].ID)
+	copy(abiCallData[4:], abiInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, abiCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*ABILookup)
+	require.True(t, ok, "expected the decoded lookup to be a ABILookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, contentTypes, lookup.ContentTypes())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestInterfaceLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	interfaceID := [4]byte{0x01, 0xff, 0xc9, 0xa7}
+
+	interfaceInputs, err := abi.IInterfaceResolver.Methods["interfaceImplementer"].Inputs.Pack(node, interfaceID)
+	require.Nil(t, err)
+
+	interfaceCallData := make([]byte, len(interfaceInputs)+4)
+	copy(interfaceCallData, abi.IInterfaceResolver.Methods["interfaceImplementer"].ID)
+	copy(interfaceCallData[4:], interfaceInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, interfaceCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*InterfaceLookup)
+	require.True(t, ok, "expected the decoded lookup to be a InterfaceLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, interfaceID, lookup.InterfaceID())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
+func TestDecodeRequestDNSRecordLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	var dnsName [32]byte
+	copy(dnsName[:], crypto.Keccak256([]byte("\x03www\x07example\x03com\x00")))
+
+	dnsRecordInputs, err := abi.IDNSRecordResolver.Methods["dnsRecord"].Inputs.Pack(node, dnsName, uint16(16))
+	require.Nil(t, err)
+
+	dnsRecordCallData := make([]byte, len(dnsRecordInputs)+4)
+	copy(dnsRecordCallData, abi.IDNSRecordResolver.Methods["dnsRecord"].ID)
+	copy(dnsRecordCallData[4:], dnsRecordInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, dnsRecordCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*DNSRecordLookup)
+	require.True(t, ok, "expected the decoded lookup to be a DNSRecordLookup")
+
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, dnsName, lookup.DNSName())
+	require.Equal(t, uint16(16), lookup.Resource())
+	require.Equal(t, *sender, lookup.senderAddress)
+	require.Equal(t, resolveCallData, lookup.requestData)
+}
+
 func TestDecodeRequestInvalidAddress(t *testing.T) {
 	req, err := DecodeRequest("0xcafebabe", "0x")
 	require.Nil(t, req)
@@ -301,3 +579,84 @@ func TestEncodeInvalidSignature(t *testing.T) {
 	require.Nil(t, responseData)
 	require.EqualError(t, err, `invalid "v" value in the signature`)
 }
+
+func TestDecodeRequestShortLookupCallData(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	for _, lookupCallData := range [][]byte{{}, {0x3b}, {0x3b, 0x3b, 0x57}} {
+		resolveCallData := makeResolveCallData(t, randomName(), lookupCallData)
+
+		req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+		require.Nil(t, req)
+		require.EqualError(t, err, "call data is too short")
+	}
+}
+
+func TestDecodeRequestEmptyDnsEncodedName(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack([]byte{}, abi.SelectorAddr)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, req)
+	require.Contains(t, err.Error(), "failed to parse dns-encoded name")
+}
+
+func TestDecodeRequestOversizedOffset(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	resolveCallData := makeResolveCallData(t, randomName(), abi.SelectorAddr)
+
+	// point the offset of the inner call data far beyond the end of the calldata
+	copy(resolveCallData[4+32:4+64], common.LeftPadBytes([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 32))
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, req)
+	require.Contains(t, err.Error(), "failed to decode resolve calldata")
+}
+
+func TestDecodeRequestEncodedLabel(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	// the client only knows the label hash of the first label
+	name := randomName()
+	labelHash, err := namehash.LabelHash(strings.Split(name, ".")[0])
+	require.Nil(t, err)
+	encodedName := namehash.EncodeLabelHash(labelHash) + name[strings.Index(name, "."):]
+
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrCallData, err := encodeCall(abi.IAddrResolver.Methods["addr"], node)
+	require.Nil(t, err)
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(makeResolveCallData(t, encodedName, addrCallData)))
+	require.Nil(t, err)
+	require.Equal(t, encodedName, req.Name())
+
+	lookup, ok := req.(UnknownLabelsLookup)
+	require.True(t, ok)
+	require.True(t, lookup.HasUnknownLabels())
+
+	// the encoded name hashes to the same node
+	requestData, err := EncodeAddrRequest(encodedName)
+	require.Nil(t, err)
+	req, err = DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+	require.True(t, req.(*AddrLookup).HasUnknownLabels())
+
+	requestData, err = EncodeAddrRequest(name)
+	require.Nil(t, err)
+	req, err = DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+	require.False(t, req.(*AddrLookup).HasUnknownLabels())
+}
@@ -0,0 +1,55 @@
+package coder
+
+import (
+	"bytes"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*ContenthashLookup)(nil)
+
+type ContenthashLookup struct {
+	LookupRequest
+}
+
+func NewContenthashLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*ContenthashLookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IContentHashResolver.Methods["contenthash"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	return &ContenthashLookup{newLookupRequest(name, node, abi.IContentHashResolver.Methods["contenthash"], senderAddress, requestData)}, nil
+}
+
+func (l *ContenthashLookup) Kind() Kind {
+	return KindContenthash
+}
+
+func (l *ContenthashLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if encodedResult, err = abi.IContentHashResolver.Methods["contenthash"].Outputs.Pack(
+		result, // bytes
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
@@ -0,0 +1,77 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareContenthashLookup(t *testing.T) (senderAddress common.Address, requestData []byte, lookup *ContenthashLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	contenthashInputs, err := abi.IContentHashResolver.Methods["contenthash"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	contenthashCallData := make([]byte, len(contenthashInputs)+4)
+	copy(contenthashCallData, abi.IContentHashResolver.Methods["contenthash"].ID)
+	copy(contenthashCallData[4:], contenthashInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, contenthashCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewContenthashLookup(name, contenthashInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+
+	return *sender, requestData, lookup
+}
+
+func TestContenthashLookupEncodeResult(t *testing.T) {
+	sender, requestData, lookup := prepareContenthashLookup(t)
+
+	result, err := randomBytes(38)
+	require.Nil(t, err)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResult(result, expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.IContentHashResolver.Methods["contenthash"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	require.Equal(t, result, decoded[0])
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestContenthashLookupNodeMismatch(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	node, err := namehash.NameHash(randomName())
+	require.Nil(t, err)
+
+	contenthashInputs, err := abi.IContentHashResolver.Methods["contenthash"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	lookup, err := NewContenthashLookup("someone-else.eth", contenthashInputs, *sender, []byte{})
+	require.Nil(t, lookup)
+	require.EqualError(t, err, "name hash does not match the lookup input")
+}
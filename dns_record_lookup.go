@@ -0,0 +1,116 @@
+package coder
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*DNSRecordLookup)(nil)
+
+type DNSRecordLookup struct {
+	LookupRequest
+	dnsName  [32]byte
+	resource uint16
+}
+
+func NewDNSRecordLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*DNSRecordLookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IDNSRecordResolver.Methods["dnsRecord"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	dnsName, ok := decoded[1].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "name" in lookup inputs`)
+	}
+
+	resource, ok := decoded[2].(uint16) // uint16
+	if !ok {
+		return nil, malformedABIError(`failed to decode "resource" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	return &DNSRecordLookup{newLookupRequest(name, node, abi.IDNSRecordResolver.Methods["dnsRecord"], senderAddress, requestData), dnsName, resource}, nil
+}
+
+func (l *DNSRecordLookup) Kind() Kind {
+	return KindDNSRecord
+}
+
+// DNSName returns the keccak256 hash of the DNS wire-format name being queried
+func (l *DNSRecordLookup) DNSName() [32]byte {
+	return l.dnsName
+}
+
+// Resource returns the DNS resource record type being queried, e.g. 1 for A
+func (l *DNSRecordLookup) Resource() uint16 {
+	return l.resource
+}
+
+// EncodeResult takes an RRset in DNS wire format. Every record must be of the
+// queried name and resource type. An empty result indicates that no records
+// were found.
+func (l *DNSRecordLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if err = l.validateRRSet(result); err != nil {
+		return nil, nil, err
+	}
+
+	if encodedResult, err = abi.IDNSRecordResolver.Methods["dnsRecord"].Outputs.Pack(
+		result, // bytes
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
+
+func (l *DNSRecordLookup) validateRRSet(rrset []byte) error {
+	for offset, i := 0, 0; offset < len(rrset); i++ {
+		nameEnd, err := dnsname.ReadName(rrset, offset)
+		if err != nil {
+			return errors.Wrapf(err, "invalid name in record %d", i)
+		}
+
+		if !bytes.Equal(crypto.Keccak256(rrset[offset:nameEnd]), l.dnsName[:]) {
+			return errors.Errorf("name in record %d does not match the lookup input", i)
+		}
+
+		// type (2) . class (2) . ttl (4) . rdlength (2)
+		if len(rrset)-nameEnd < 10 {
+			return errors.Errorf("record %d is truncated", i)
+		}
+
+		if rrType := binary.BigEndian.Uint16(rrset[nameEnd:]); rrType != l.resource {
+			return errors.Errorf("type of record %d does not match the lookup input", i)
+		}
+
+		rdLength := int(binary.BigEndian.Uint16(rrset[nameEnd+8:]))
+		offset = nameEnd + 10 + rdLength
+		if offset > len(rrset) {
+			return errors.Errorf("record %d is truncated", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// a.example.com. 3600 IN A 1.2.3.4
+var testARecord = hexutil.MustDecode("0x0161076578616d706c6503636f6d000001000100000e10000401020304")
+
+func prepareDNSRecordLookup(t *testing.T, dnsName []byte, resource uint16) (senderAddress common.Address, requestData []byte, lookup *DNSRecordLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	var dnsNameHash [32]byte
+	copy(dnsNameHash[:], crypto.Keccak256(dnsName))
+
+	dnsRecordInputs, err := abi.IDNSRecordResolver.Methods["dnsRecord"].Inputs.Pack(node, dnsNameHash, resource)
+	require.Nil(t, err)
+
+	dnsRecordCallData := make([]byte, len(dnsRecordInputs)+4)
+	copy(dnsRecordCallData, abi.IDNSRecordResolver.Methods["dnsRecord"].ID)
+	copy(dnsRecordCallData[4:], dnsRecordInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, dnsRecordCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewDNSRecordLookup(name, dnsRecordInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, dnsNameHash, lookup.DNSName())
+	require.Equal(t, resource, lookup.Resource())
+
+	return *sender, requestData, lookup
+}
+
+func TestDNSRecordLookupEncodeResult(t *testing.T) {
+	dnsName := testARecord[:15]
+	sender, requestData, lookup := prepareDNSRecordLookup(t, dnsName, 1)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	for _, result := range [][]byte{
+		testARecord,
+		append(append([]byte{}, testARecord...), testARecord...),
+		{},
+	} {
+		resultData, hash, err := lookup.EncodeResult(result, expires)
+		require.Nil(t, err)
+
+		decoded, err := abi.IDNSRecordResolver.Methods["dnsRecord"].Outputs.Unpack(resultData)
+		require.Nil(t, err)
+
+		require.Equal(t, result, decoded[0])
+		require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+	}
+}
+
+func TestDNSRecordLookupEncodeResultInvalidRRSet(t *testing.T) {
+	_, _, otherLookup := prepareDNSRecordLookup(t, []byte("\x01b\x07example\x03com\x00"), 1)
+	_, _, aaaaLookup := prepareDNSRecordLookup(t, testARecord[:15], 28)
+	_, _, lookup := prepareDNSRecordLookup(t, testARecord[:15], 1)
+
+	compressed := append([]byte{0x01, 'a', 0xc0, 0x0c}, testARecord[15:]...)
+
+	for _, tc := range []struct {
+		lookup *DNSRecordLookup
+		result []byte
+		err    string
+	}{
+		{otherLookup, testARecord, "name in record 0 does not match the lookup input"},
+		{aaaaLookup, testARecord, "type of record 0 does not match the lookup input"},
+		{lookup, testARecord[:len(testARecord)-1], "record 0 is truncated"},
+		{lookup, testARecord[:20], "record 0 is truncated"},
+		{lookup, testARecord[:10], "invalid name in record 0: name is truncated"},
+		{lookup, compressed, "invalid name in record 0: compressed or invalid label"},
+		{lookup, append(append([]byte{}, testARecord...), 0x01), "invalid name in record 1: name is truncated"},
+	} {
+		resultData, hash, err := tc.lookup.EncodeResult(tc.result, makeExpires())
+		require.Nil(t, resultData)
+		require.Nil(t, hash)
+		require.EqualError(t, err, tc.err)
+	}
+}
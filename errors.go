@@ -0,0 +1,71 @@
+package coder
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// Errors returned by DecodeRequest and the lookup constructors. Use errors.Is
+// to check for them, as they may be wrapped with more context.
+var (
+	ErrInvalidSender  = errors.New("sender is not a valid address")
+	ErrInvalidHex     = errors.New("data is not a valid hex string")
+	ErrNotResolveCall = errors.New("data is not a resolve call")
+	ErrInvalidName    = errors.New("invalid name")
+	ErrMalformedABI   = errors.New("malformed ABI encoding")
+	ErrNodeMismatch   = errors.New("name hash does not match the lookup input")
+)
+
+// ErrNonCanonicalABI is only returned in strict mode, see Registry.SetStrict
+var ErrNonCanonicalABI = errors.New("non-canonical ABI encoding")
+
+// Errors returned by Verify
+var (
+	ErrSignatureExpired = errors.New("signature expired")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// UnsupportedSelectorError is returned by DecodeRequest when no lookup is
+// registered for the selector of the resolver function call
+type UnsupportedSelectorError struct {
+	Selector [4]byte
+}
+
+func (e *UnsupportedSelectorError) Error() string {
+	return "unsupported lookup: " + hexutil.Encode(e.Selector[:])
+}
+
+// classifiedError attaches one of the sentinel errors above to an error while
+// keeping its message, so that errors.Is matches both the sentinel and the cause
+type classifiedError struct {
+	class error
+	msg   string
+	cause error
+}
+
+func (e *classifiedError) Error() string {
+	if e.cause == nil {
+		return e.msg
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.cause
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+func malformedABIError(msg string) error {
+	return &classifiedError{ErrMalformedABI, msg, nil}
+}
+
+func wrapMalformedABIError(err error, msg string) error {
+	return &classifiedError{ErrMalformedABI, msg, err}
+}
+
+func wrapInvalidNameError(err error, msg string) error {
+	return &classifiedError{ErrInvalidName, msg, err}
+}
@@ -0,0 +1,94 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRequestErrorClassification(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+	addrCallData := append(append([]byte{}, abi.SelectorAddr...), addrInputs...)
+
+	dn := []byte{0x03, 'f', 'o', 'o'} // missing terminating zero-length label
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, addrCallData)
+	require.Nil(t, err)
+	invalidNameCallData := append(append([]byte{}, abi.SelectorResolve...), resolveInputs...)
+
+	for _, tc := range []struct {
+		sender string
+		data   string
+		target error
+	}{
+		{"0xcafebabe", "0x", ErrInvalidSender},
+		{sender.Hex(), "zebra", ErrInvalidHex},
+		{sender.Hex(), "0x", ErrNotResolveCall},
+		{sender.Hex(), hexutil.Encode(abi.SelectorResolve) + "00", ErrMalformedABI},
+		{sender.Hex(), hexutil.Encode(invalidNameCallData), ErrInvalidName},
+		{sender.Hex(), hexutil.Encode(makeResolveCallData(t, name, abi.SelectorAddr)), ErrMalformedABI},
+		{sender.Hex(), hexutil.Encode(makeResolveCallData(t, randomName(), addrCallData)), ErrNodeMismatch},
+	} {
+		req, err := DecodeRequest(tc.sender, tc.data)
+		require.Nil(t, req)
+		require.True(t, errors.Is(err, tc.target), "expected %q to be %q", err, tc.target)
+	}
+}
+
+func TestDecodeRequestUnsupportedSelectorError(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	resolveCallData := makeResolveCallData(t, randomName(), []byte{0xde, 0xad, 0xbe, 0xef})
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, req)
+
+	var unsupported *UnsupportedSelectorError
+	require.True(t, errors.As(err, &unsupported))
+	require.Equal(t, [4]byte{0xde, 0xad, 0xbe, 0xef}, unsupported.Selector)
+	require.EqualError(t, err, "unsupported lookup: 0xdeadbeef")
+}
+
+func TestLookupConstructorErrorClassification(t *testing.T) {
+	node, err := namehash.NameHash(randomName())
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	_, err = NewAddrLookup(randomName(), addrInputs, common.Address{}, nil)
+	require.True(t, errors.Is(err, ErrNodeMismatch))
+	require.EqualError(t, err, "name hash does not match the lookup input")
+
+	_, err = NewTextLookup(randomName(), addrInputs[:16], common.Address{}, nil)
+	require.True(t, errors.Is(err, ErrMalformedABI))
+	require.Contains(t, err.Error(), "failed to decode lookup inputs: ")
+
+	_, err = NewMulticoinAddrLookup(randomName(), addrInputs, common.Address{}, nil)
+	require.True(t, errors.Is(err, ErrMalformedABI))
+}
+
+func TestVerifyErrors(t *testing.T) {
+	signer, err := randomAddress()
+	require.Nil(t, err)
+
+	now := time.Now()
+	response := &Response{Expires: uint64(now.Unix()), Signer: *signer}
+
+	require.True(t, errors.Is(Verify(response, nil, now), ErrInvalidSignature))
+	require.True(t, errors.Is(Verify(response, []common.Address{*signer}, now.Add(time.Second)), ErrSignatureExpired))
+}
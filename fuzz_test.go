@@ -0,0 +1,63 @@
+package coder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzDecodeRequest checks that DecodeRequest never panics and always returns
+// either a lookup or an error. Run with:
+//
+//	go test -run '^$' -fuzz FuzzDecodeRequest
+func FuzzDecodeRequest(f *testing.F) {
+	name := "pete.cbdev.eth"
+	node, err := namehash.NameHash(name)
+	require.Nil(f, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(f, err)
+	addrCallData := append(append([]byte{}, abi.SelectorAddr...), addrInputs...)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack([][]byte{addrCallData, {0x01}})
+	require.Nil(f, err)
+
+	for _, encode := range []func() ([]byte, error){
+		func() ([]byte, error) { return EncodeAddrRequest(name) },
+		func() ([]byte, error) { return EncodeMulticoinAddrRequest(name, big.NewInt(60)) },
+		func() ([]byte, error) { return EncodeTextRequest(name, "avatar") },
+		func() ([]byte, error) { return EncodeContenthashRequest(name) },
+		func() ([]byte, error) { return EncodeDNSRecordRequest(name, node, 1) },
+		func() ([]byte, error) {
+			return EncodeRequest(name, append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...))
+		},
+		func() ([]byte, error) { return EncodeRequest(name, []byte{0x3b, 0x3b}) },
+	} {
+		requestData, err := encode()
+		require.Nil(f, err)
+		f.Add(requestData)
+	}
+	f.Add([]byte{})
+	f.Add(abi.SelectorResolve)
+
+	sender := "0x000000000000000000000000000000000000c0de"
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lookup, err := DecodeRequest(sender, hexutil.Encode(data))
+		if (lookup == nil) == (err == nil) {
+			t.Fatalf("expected either a lookup or an error, got %v, %v", lookup, err)
+		}
+
+		if multicall, ok := lookup.(*MulticallLookup); ok {
+			for _, call := range multicall.Calls() {
+				if (call.Lookup == nil) == (call.Err == nil) {
+					t.Fatalf("expected either a lookup or an error for call %x", call.CallData)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"math/big"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/coins"
+	"github.com/pkg/errors"
+)
+
+// AddrQuery is an address lookup for a name and coin type. addr(bytes32) and
+// addr(bytes32,uint256) with coin type 60 are the same query.
+type AddrQuery struct {
+	Name     string
+	Node     [32]byte
+	CoinType *big.Int
+}
+
+// NewAddrQuery returns the query for an AddrLookup or MulticoinAddrLookup. ok
+// is false for other lookups.
+func NewAddrQuery(lookup coder.Lookup) (query *AddrQuery, ok bool) {
+	switch l := lookup.(type) {
+	case *coder.AddrLookup:
+		return &AddrQuery{l.Name(), l.Node(), big.NewInt(coins.CoinTypeETH)}, true
+	case *coder.MulticoinAddrLookup:
+		return &AddrQuery{l.Name(), l.Node(), l.CoinType()}, true
+	}
+	return nil, false
+}
+
+// AddrResolver fetches the address for a query in its binary form, returning
+// ErrNotFound or an empty address if there is none
+type AddrResolver interface {
+	ResolveAddr(ctx context.Context, query *AddrQuery) (addr []byte, err error)
+}
+
+// AddrResolverFunc adapts a function to the AddrResolver interface
+type AddrResolverFunc func(ctx context.Context, query *AddrQuery) (addr []byte, err error)
+
+func (f AddrResolverFunc) ResolveAddr(ctx context.Context, query *AddrQuery) (addr []byte, err error) {
+	return f(ctx, query)
+}
+
+// AddrFallback returns the coin type to query when there is no address for the
+// coin type of a query. ok is false if the rule does not apply.
+type AddrFallback func(coinType *big.Int) (fallback *big.Int, ok bool)
+
+// FallbackToDefaultEVM falls back from the coin types of EVM chains to the
+// default EVM address, as in ENSIP-19. Coin type 60 does not fall back; add
+// FallbackTo(60, coins.CoinTypeDefaultEVM) to include it.
+// https://docs.ens.domains/ensip/19
+func FallbackToDefaultEVM(coinType *big.Int) (fallback *big.Int, ok bool) {
+	if _, isChain := coins.EVMChainID(coinType); !isChain || coinType.Cmp(big.NewInt(coins.CoinTypeETH)) == 0 {
+		return nil, false
+	}
+	return big.NewInt(coins.CoinTypeDefaultEVM), true
+}
+
+// FallbackToETH falls back from the coin types of EVM chains to coin type 60,
+// the address returned by addr(bytes32)
+func FallbackToETH(coinType *big.Int) (fallback *big.Int, ok bool) {
+	if _, isChain := coins.EVMChainID(coinType); !isChain || coinType.Cmp(big.NewInt(coins.CoinTypeETH)) == 0 {
+		return nil, false
+	}
+	return big.NewInt(coins.CoinTypeETH), true
+}
+
+// FallbackTo returns a rule falling back from one coin type to another
+func FallbackTo(from int64, to int64) AddrFallback {
+	return func(coinType *big.Int) (*big.Int, bool) {
+		if coinType.Cmp(big.NewInt(from)) != 0 {
+			return nil, false
+		}
+		return big.NewInt(to), true
+	}
+}
+
+// AddrPolicy configures how address queries are resolved
+type AddrPolicy struct {
+	// Fallbacks are applied in order to the coin type of a query that has no
+	// address, and the address of the first fallback coin type that has one is
+	// returned
+	Fallbacks []AddrFallback
+}
+
+// NewAddrBackend returns a backend that answers addr lookups with the resolver,
+// applying the fallbacks of the policy. Other lookups are passed to next, or
+// return ErrNotFound if next is nil.
+func NewAddrBackend(resolver AddrResolver, policy AddrPolicy, next Backend) Backend {
+	return BackendFunc(func(ctx context.Context, lookup coder.Lookup) ([]byte, error) {
+		query, ok := NewAddrQuery(lookup)
+		if !ok {
+			if next == nil {
+				return nil, ErrNotFound
+			}
+			return next.Resolve(ctx, lookup)
+		}
+		return policy.resolve(ctx, resolver, query)
+	})
+}
+
+func (p *AddrPolicy) resolve(ctx context.Context, resolver AddrResolver, query *AddrQuery) ([]byte, error) {
+	addr, err := resolveAddr(ctx, resolver, query)
+	if !errors.Is(err, ErrNotFound) {
+		return addr, err
+	}
+
+	for _, fallback := range p.Fallbacks {
+		coinType, ok := fallback(query.CoinType)
+		if !ok {
+			continue
+		}
+
+		addr, err := resolveAddr(ctx, resolver, &AddrQuery{query.Name, query.Node, coinType})
+		if !errors.Is(err, ErrNotFound) {
+			return addr, err
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// resolveAddr returns ErrNotFound for empty addresses
+func resolveAddr(ctx context.Context, resolver AddrResolver, query *AddrQuery) ([]byte, error) {
+	addr, err := resolver.ResolveAddr(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(addr) == 0 {
+		return nil, ErrNotFound
+	}
+	return addr, nil
+}
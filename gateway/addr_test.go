@@ -0,0 +1,171 @@
+package gateway
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/coins"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDefaultAddress = common.HexToAddress("0x000000000000000000000000000000000000defa")
+	testBaseAddress    = common.HexToAddress("0x0000000000000000000000000000000000002105")
+)
+
+// testAddrResolver has an address for ETH, Base and the default EVM address,
+// and records the coin types queried
+type testAddrResolver struct {
+	queried []string
+}
+
+func (r *testAddrResolver) ResolveAddr(ctx context.Context, query *AddrQuery) ([]byte, error) {
+	r.queried = append(r.queried, query.CoinType.String())
+
+	node, err := namehash.NameHash(testName)
+	if err != nil {
+		return nil, err
+	}
+	if query.Name != testName || query.Node != node {
+		return nil, ErrNotFound
+	}
+
+	switch query.CoinType.Int64() {
+	case coins.CoinTypeETH:
+		return testAddress.Bytes(), nil
+	case 0x80002105:
+		return testBaseAddress.Bytes(), nil
+	case coins.CoinTypeDefaultEVM:
+		return testDefaultAddress.Bytes(), nil
+	case coins.CoinTypeBTC:
+		// set, but empty
+		return []byte{}, nil
+	case coins.CoinTypeLTC:
+		return nil, errors.New("database is down")
+	}
+	return nil, ErrNotFound
+}
+
+// lookupDecoder returns a function decoding the result of an Encode*Request
+// function into a lookup
+func lookupDecoder(t *testing.T) func(requestData []byte, err error) coder.Lookup {
+	return func(requestData []byte, err error) coder.Lookup {
+		require.Nil(t, err)
+		lookup, err := coder.DecodeRequest(testSender.Hex(), hexutil.Encode(requestData))
+		require.Nil(t, err)
+		return lookup
+	}
+}
+
+func TestNewAddrQuery(t *testing.T) {
+	decode := lookupDecoder(t)
+
+	node, err := namehash.NameHash(testName)
+	require.Nil(t, err)
+
+	for _, lookup := range []coder.Lookup{
+		decode(coder.EncodeAddrRequest(testName)),
+		decode(coder.EncodeMulticoinAddrRequest(testName, big.NewInt(coins.CoinTypeETH))),
+	} {
+		query, ok := NewAddrQuery(lookup)
+		require.True(t, ok)
+		require.Equal(t, &AddrQuery{testName, node, big.NewInt(coins.CoinTypeETH)}, query)
+	}
+
+	query, ok := NewAddrQuery(decode(coder.EncodeTextRequest(testName, "avatar")))
+	require.False(t, ok)
+	require.Nil(t, query)
+}
+
+func TestAddrBackend(t *testing.T) {
+	decode := lookupDecoder(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		policy   AddrPolicy
+		coinType int64
+		result   []byte
+		queried  []string
+	}{
+		// no fallbacks
+		{AddrPolicy{}, coins.CoinTypeETH, testAddress.Bytes(), []string{"60"}},
+		{AddrPolicy{}, 0x8000000a, nil, []string{"2147483658"}},
+		{AddrPolicy{}, coins.CoinTypeBTC, nil, []string{"0"}},
+		// chains with an address of their own don't fall back
+		{AddrPolicy{[]AddrFallback{FallbackToDefaultEVM}}, 0x80002105, testBaseAddress.Bytes(), []string{"2147492101"}},
+		{AddrPolicy{[]AddrFallback{FallbackToDefaultEVM}}, 0x8000000a, testDefaultAddress.Bytes(), []string{"2147483658", "2147483648"}},
+		// the default EVM address and non-EVM coin types don't fall back
+		{AddrPolicy{[]AddrFallback{FallbackToDefaultEVM}}, coins.CoinTypeDefaultEVM, testDefaultAddress.Bytes(), []string{"2147483648"}},
+		{AddrPolicy{[]AddrFallback{FallbackToDefaultEVM}}, coins.CoinTypeBTC, nil, []string{"0"}},
+		{AddrPolicy{[]AddrFallback{FallbackToETH}}, 0x8000000a, testAddress.Bytes(), []string{"2147483658", "60"}},
+		{AddrPolicy{[]AddrFallback{FallbackToETH}}, 0x80002105, testBaseAddress.Bytes(), []string{"2147492101"}},
+		{AddrPolicy{[]AddrFallback{FallbackToETH}}, coins.CoinTypeDefaultEVM, testDefaultAddress.Bytes(), []string{"2147483648"}},
+		{AddrPolicy{[]AddrFallback{FallbackToETH}}, coins.CoinTypeBTC, nil, []string{"0"}},
+		// the default EVM address takes precedence when listed first
+		{AddrPolicy{[]AddrFallback{FallbackToDefaultEVM, FallbackToETH}}, 0x8000000a, testDefaultAddress.Bytes(), []string{"2147483658", "2147483648"}},
+		// custom rules, applied in order
+		{AddrPolicy{[]AddrFallback{FallbackTo(coins.CoinTypeBTC, 3), FallbackTo(coins.CoinTypeBTC, coins.CoinTypeETH)}}, coins.CoinTypeBTC, testAddress.Bytes(), []string{"0", "3", "60"}},
+	} {
+		resolver := &testAddrResolver{}
+		backend := NewAddrBackend(resolver, tc.policy, nil)
+
+		result, err := backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest(testName, big.NewInt(tc.coinType))))
+		if tc.result == nil {
+			require.True(t, errors.Is(err, ErrNotFound), tc.coinType)
+		} else {
+			require.Nil(t, err, tc.coinType)
+			require.Equal(t, tc.result, result, tc.coinType)
+		}
+		require.Equal(t, tc.queried, resolver.queried, tc.coinType)
+	}
+}
+
+func TestAddrBackendAddr(t *testing.T) {
+	decode := lookupDecoder(t)
+	ctx := context.Background()
+	resolver := &testAddrResolver{}
+	backend := NewAddrBackend(resolver, AddrPolicy{[]AddrFallback{FallbackToDefaultEVM}}, nil)
+
+	// addr(bytes32) is the same query as coin type 60, which doesn't fall back
+	result, err := backend.Resolve(ctx, decode(coder.EncodeAddrRequest(testName)))
+	require.Nil(t, err)
+	require.Equal(t, testAddress.Bytes(), result)
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodeAddrRequest("other.eth")))
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Equal(t, []string{"60", "60"}, resolver.queried)
+
+	// unless configured to
+	resolver = &testAddrResolver{}
+	backend = NewAddrBackend(resolver, AddrPolicy{[]AddrFallback{FallbackTo(coins.CoinTypeETH, coins.CoinTypeDefaultEVM)}}, nil)
+	_, err = backend.Resolve(ctx, decode(coder.EncodeAddrRequest("other.eth")))
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Equal(t, []string{"60", "2147483648"}, resolver.queried)
+}
+
+func TestAddrBackendErrors(t *testing.T) {
+	decode := lookupDecoder(t)
+	ctx := context.Background()
+	resolver := &testAddrResolver{}
+	backend := NewAddrBackend(resolver, AddrPolicy{[]AddrFallback{FallbackTo(coins.CoinTypeLTC, coins.CoinTypeETH)}}, nil)
+
+	// errors other than ErrNotFound don't fall back
+	_, err := backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest(testName, big.NewInt(coins.CoinTypeLTC))))
+	require.EqualError(t, err, "database is down")
+	require.Equal(t, []string{"2"}, resolver.queried)
+
+	// other lookups go to the next backend
+	_, err = backend.Resolve(ctx, decode(coder.EncodeTextRequest(testName, "avatar")))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	backend = NewAddrBackend(resolver, AddrPolicy{}, BackendFunc(testBackend))
+	result, err := backend.Resolve(ctx, decode(coder.EncodeTextRequest(testName, "avatar")))
+	require.Nil(t, err)
+	require.Equal(t, []byte("https://example.com/avatar.png"), result)
+}
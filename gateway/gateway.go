@@ -0,0 +1,241 @@
+// Package gateway implements an EIP-3668 (CCIP-Read) gateway server for the
+// offchain resolver.
+//
+// https://eips.ethereum.org/EIPS/eip-3668#gateway-interface
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned by a Backend when there is no record for a lookup.
+// The handler then signs the empty value of the resolver function, such as the
+// zero address or an empty string, as a resolver without the record would.
+var ErrNotFound = errors.New("record not found")
+
+// ErrInvalidRecord is returned when the record returned by the Backend fails
+// validation and is not signed
+var ErrInvalidRecord = errors.New("invalid record")
+
+// Backend fetches the raw result for a lookup, in the format expected by the
+// EncodeResult method of the lookup
+type Backend interface {
+	Resolve(ctx context.Context, lookup coder.Lookup) (result []byte, err error)
+}
+
+// BackendFunc adapts a function to the Backend interface
+type BackendFunc func(ctx context.Context, lookup coder.Lookup) (result []byte, err error)
+
+func (f BackendFunc) Resolve(ctx context.Context, lookup coder.Lookup) (result []byte, err error) {
+	return f(ctx, lookup)
+}
+
+// Handler serves CCIP-Read requests, both GET /{sender}/{data}.json and POST
+// with a {"sender": ..., "data": ...} body, and responds with {"data": ...}
+type Handler struct {
+	Backend Backend
+	Signer  coder.Signer
+	// TTL is how long a signed response is valid for
+	TTL time.Duration
+	// Registry is used to decode requests, coder.DefaultRegistry if nil
+	Registry *coder.Registry
+	// ValidateText makes the handler refuse to sign text records that fail
+	// TextLookup.ValidateResult, such as malformed avatar URIs
+	ValidateText bool
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+func NewHandler(backend Backend, signer coder.Signer, ttl time.Duration) *Handler {
+	return &Handler{Backend: backend, Signer: signer, TTL: ttl}
+}
+
+type request struct {
+	Sender string `json:"sender"`
+	Data   string `json:"data"`
+}
+
+type response struct {
+	Data string `json:"data"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// maximum size of a POST body
+const maxRequestSize = 64 * 1024
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		var ok bool
+		if req, ok = parsePath(r.URL.Path); !ok {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	responseData, status, err := h.handle(r.Context(), req)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response{hexutil.Encode(responseData)})
+}
+
+func (h *Handler) handle(ctx context.Context, req request) (responseData []byte, status int, err error) {
+	registry := h.Registry
+	if registry == nil {
+		registry = coder.DefaultRegistry
+	}
+
+	lookup, err := registry.DecodeRequest(req.Sender, req.Data)
+	if err != nil {
+		var unsupported *coder.UnsupportedSelectorError
+		if errors.As(err, &unsupported) {
+			return nil, http.StatusNotFound, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+
+	var result []byte
+	if multicall, ok := lookup.(*coder.MulticallLookup); ok {
+		result, err = h.resolveMulticall(ctx, multicall)
+	} else {
+		result, err = h.resolve(ctx, lookup)
+	}
+	if errors.Is(err, ErrNotFound) {
+		if empty, ok := emptyResult(lookup); ok {
+			result, err = empty, nil
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, http.StatusNotFound, err
+		}
+		if errors.Is(err, ErrInvalidRecord) {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		return nil, http.StatusInternalServerError, errors.New("failed to resolve the lookup")
+	}
+
+	if responseData, err = coder.SignAndEncode(h.Signer, lookup, result, h.TTL); err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to encode the response")
+	}
+
+	return responseData, http.StatusOK, nil
+}
+
+// resolve resolves a single lookup with the backend
+func (h *Handler) resolve(ctx context.Context, lookup coder.Lookup) ([]byte, error) {
+	result, err := h.Backend.Resolve(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	if l, ok := lookup.(*coder.TextLookup); ok && h.ValidateText {
+		if err := l.ValidateResult(result); err != nil {
+			return nil, errors.Wrap(ErrInvalidRecord, err.Error())
+		}
+	}
+	return result, nil
+}
+
+// emptyResult returns the result for a built-in lookup without a record, which
+// encodes the zero value of the resolver function's return type
+func emptyResult(lookup coder.Lookup) (result []byte, ok bool) {
+	switch lookup.Kind() {
+	case coder.KindAddr, coder.KindInterface:
+		return make([]byte, 20), true // address
+	case coder.KindPubkey:
+		return make([]byte, 64), true // bytes32 x . bytes32 y
+	case coder.KindABI:
+		result, err := abi.IABIResolver.Methods["ABI"].Outputs.Pack(new(big.Int), []byte{})
+		return result, err == nil // (uint256,bytes)
+	case coder.KindMulticoinAddr, coder.KindText, coder.KindContenthash, coder.KindName, coder.KindDNSRecord:
+		return []byte{}, true // bytes or string
+	}
+	return nil, false
+}
+
+// resolveMulticall resolves each call in the batch and returns the encoded
+// results. Calls that failed to decode, have no record or have an invalid
+// record return empty bytes.
+func (h *Handler) resolveMulticall(ctx context.Context, multicall *coder.MulticallLookup) ([]byte, error) {
+	calls := multicall.Calls()
+	results := make([][]byte, len(calls))
+
+	for i, call := range calls {
+		if call.Lookup == nil {
+			continue
+		}
+
+		result, err := h.resolve(ctx, call.Lookup)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidRecord) {
+				continue
+			}
+			return nil, err
+		}
+		results[i] = result
+	}
+
+	encodedResult, _, err := multicall.EncodeResults(results, 0)
+	return encodedResult, err
+}
+
+// parsePath extracts the sender and data from a path ending in
+// /{sender}/{data}.json, allowing the handler to be mounted under a prefix
+func parsePath(path string) (req request, ok bool) {
+	if !strings.HasSuffix(path, ".json") {
+		return request{}, false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(path, ".json"), "/")
+	if len(parts) < 3 {
+		return request{}, false
+	}
+
+	return request{parts[len(parts)-2], parts[len(parts)-1]}, true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,327 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testName = "pete.cbdev.eth"
+
+var (
+	testSender  = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	testAddress = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+)
+
+func testBackend(ctx context.Context, lookup coder.Lookup) ([]byte, error) {
+	if lookup.Name() != testName {
+		return nil, ErrNotFound
+	}
+
+	switch l := lookup.(type) {
+	case *coder.AddrLookup:
+		return testAddress.Bytes(), nil
+	case *coder.TextLookup:
+		if l.Key() == "fail" {
+			return nil, errors.New("database is down")
+		}
+		if l.Key() == "avatar" {
+			return []byte("https://example.com/avatar.png"), nil
+		}
+		if l.Key() == "com.github" {
+			return []byte("-not-a-username"), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func newTestHandler(t *testing.T) (*Handler, coder.Signer) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	signer := coder.NewPrivateKeySigner(key)
+	return NewHandler(BackendFunc(testBackend), signer, 5*time.Minute), signer
+}
+
+func serve(handler http.Handler, method string, path string, body []byte) (int, map[string]string) {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var res map[string]string
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	return rec.Code, res
+}
+
+func verifyResponse(t *testing.T, signer coder.Signer, requestData []byte, responseData string) *coder.Response {
+	lookup, err := coder.DecodeRequest(testSender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+
+	response, err := coder.DecodeResponse(lookup, hexutil.MustDecode(responseData))
+	require.Nil(t, err)
+	require.Nil(t, coder.Verify(response, []common.Address{signer.Address()}, time.Now()))
+
+	return response
+}
+
+func TestHandlerGet(t *testing.T) {
+	handler, signer := newTestHandler(t)
+
+	requestData, err := coder.EncodeAddrRequest(testName)
+	require.Nil(t, err)
+
+	for _, path := range []string{
+		"/" + testSender.Hex() + "/" + hexutil.Encode(requestData) + ".json",
+		"/gateway/v1/" + testSender.Hex() + "/" + hexutil.Encode(requestData) + ".json",
+	} {
+		status, res := serve(handler, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		response := verifyResponse(t, signer, requestData, res["data"])
+
+		decoded, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(response.Result)
+		require.Nil(t, err)
+		require.Equal(t, testAddress, decoded[0])
+	}
+}
+
+func TestHandlerPost(t *testing.T) {
+	handler, signer := newTestHandler(t)
+
+	requestData, err := coder.EncodeTextRequest(testName, "avatar")
+	require.Nil(t, err)
+
+	body, err := json.Marshal(map[string]string{"sender": testSender.Hex(), "data": hexutil.Encode(requestData)})
+	require.Nil(t, err)
+
+	status, res := serve(handler, http.MethodPost, "/", body)
+	require.Equal(t, http.StatusOK, status)
+
+	response := verifyResponse(t, signer, requestData, res["data"])
+
+	decoded, err := abi.ITextResolver.Methods["text"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/avatar.png", decoded[0])
+}
+
+func TestHandlerMulticall(t *testing.T) {
+	handler, signer := newTestHandler(t)
+
+	node, err := namehash.NameHash(testName)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	textInputs, err := abi.ITextResolver.Methods["text"].Inputs.Pack(node, "url")
+	require.Nil(t, err)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack([][]byte{
+		append(append([]byte{}, abi.SelectorAddr...), addrInputs...),
+		append(append([]byte{}, abi.SelectorText...), textInputs...),
+		{0xde, 0xad, 0xbe, 0xef},
+	})
+	require.Nil(t, err)
+
+	requestData, err := coder.EncodeRequest(testName, append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...))
+	require.Nil(t, err)
+
+	status, res := serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(requestData)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+
+	response := verifyResponse(t, signer, requestData, res["data"])
+
+	decoded, err := abi.IMulticallable.Methods["multicall"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+
+	results := decoded[0].([][]byte)
+	require.Len(t, results, 3)
+
+	addrResult, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(results[0])
+	require.Nil(t, err)
+	require.Equal(t, testAddress, addrResult[0])
+	require.Empty(t, results[1])
+	require.Empty(t, results[2])
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	addrRequest, err := coder.EncodeAddrRequest(testName)
+	require.Nil(t, err)
+
+	failingRequest, err := coder.EncodeTextRequest(testName, "fail")
+	require.Nil(t, err)
+
+	unsupportedRequest, err := coder.EncodeRequest(testName, []byte{0xde, 0xad, 0xbe, 0xef})
+	require.Nil(t, err)
+
+	for _, tc := range []struct {
+		method  string
+		path    string
+		body    string
+		status  int
+		message string
+	}{
+		{http.MethodGet, "/" + testSender.Hex() + "/" + hexutil.Encode(addrRequest), "", http.StatusNotFound, "not found"},
+		{http.MethodGet, "/0xcafe/" + hexutil.Encode(addrRequest) + ".json", "", http.StatusBadRequest, "sender is not a valid address"},
+		{http.MethodGet, "/" + testSender.Hex() + "/zebra.json", "", http.StatusBadRequest, "data is not a valid hex string"},
+		{http.MethodGet, "/" + testSender.Hex() + "/" + hexutil.Encode(failingRequest) + ".json", "", http.StatusInternalServerError, "failed to resolve the lookup"},
+		{http.MethodGet, "/" + testSender.Hex() + "/" + hexutil.Encode(unsupportedRequest) + ".json", "", http.StatusNotFound, "unsupported lookup: 0xdeadbeef"},
+		{http.MethodPost, "/", "{", http.StatusBadRequest, "invalid request body"},
+		{http.MethodPut, "/", "", http.StatusMethodNotAllowed, "method not allowed"},
+	} {
+		status, res := serve(handler, tc.method, tc.path, []byte(tc.body))
+		require.Equal(t, tc.status, status, tc.path)
+		require.Equal(t, tc.message, res["message"], tc.path)
+	}
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	handler, signer := newTestHandler(t)
+
+	// lookups without a record are answered with the empty value
+	addrRequest, err := coder.EncodeAddrRequest("unknown.eth")
+	require.Nil(t, err)
+
+	status, res := serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(addrRequest)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+
+	response := verifyResponse(t, signer, addrRequest, res["data"])
+
+	decoded, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+	require.Equal(t, common.Address{}, decoded[0])
+
+	textRequest, err := coder.EncodeTextRequest(testName, "url")
+	require.Nil(t, err)
+
+	status, res = serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(textRequest)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+
+	response = verifyResponse(t, signer, textRequest, res["data"])
+
+	decoded, err = abi.ITextResolver.Methods["text"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+	require.Equal(t, "", decoded[0])
+
+	pubkeyRequest, err := coder.EncodePubkeyRequest(testName)
+	require.Nil(t, err)
+
+	status, res = serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(pubkeyRequest)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+
+	response = verifyResponse(t, signer, pubkeyRequest, res["data"])
+
+	decoded, err = abi.IPubkeyResolver.Methods["pubkey"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+	require.Equal(t, [32]byte{}, decoded[0])
+	require.Equal(t, [32]byte{}, decoded[1])
+
+	// custom lookups have no known empty value
+	handler.Registry = coder.NewRegistry()
+	require.Nil(t, handler.Registry.Register([]byte{0xca, 0xfe, 0xba, 0xbe}, func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (coder.Lookup, error) {
+		return &customLookup{coder.NewLookupRequest(name, [32]byte{}, [4]byte{0xca, 0xfe, 0xba, 0xbe}, senderAddress, requestData)}, nil
+	}))
+
+	customRequest, err := coder.EncodeRequest(testName, []byte{0xca, 0xfe, 0xba, 0xbe})
+	require.Nil(t, err)
+
+	status, res = serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(customRequest)+".json", nil)
+	require.Equal(t, http.StatusNotFound, status)
+	require.Equal(t, "record not found", res["message"])
+}
+
+type customLookup struct {
+	coder.LookupRequest
+}
+
+func (l *customLookup) EncodeResult(result []byte, expires uint64) ([]byte, []byte, error) {
+	return result, l.ResultHash(result, expires), nil
+}
+
+func TestHandlerValidateText(t *testing.T) {
+	handler, signer := newTestHandler(t)
+
+	avatarRequest, err := coder.EncodeTextRequest(testName, "avatar")
+	require.Nil(t, err)
+
+	gitHubRequest, err := coder.EncodeTextRequest(testName, "com.github")
+	require.Nil(t, err)
+
+	status, res := serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(gitHubRequest)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+	verifyResponse(t, signer, gitHubRequest, res["data"])
+
+	handler.ValidateText = true
+
+	status, res = serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(gitHubRequest)+".json", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, status)
+	require.Equal(t, `invalid value for "com.github": value must be a GitHub username: invalid record`, res["message"])
+
+	status, res = serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(avatarRequest)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+	verifyResponse(t, signer, avatarRequest, res["data"])
+}
+
+func TestHandlerMulticallValidateText(t *testing.T) {
+	handler, signer := newTestHandler(t)
+	handler.ValidateText = true
+
+	node, err := namehash.NameHash(testName)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	gitHubInputs, err := abi.ITextResolver.Methods["text"].Inputs.Pack(node, "com.github")
+	require.Nil(t, err)
+
+	avatarInputs, err := abi.ITextResolver.Methods["text"].Inputs.Pack(node, "avatar")
+	require.Nil(t, err)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack([][]byte{
+		append(append([]byte{}, abi.SelectorAddr...), addrInputs...),
+		append(append([]byte{}, abi.SelectorText...), gitHubInputs...),
+		append(append([]byte{}, abi.SelectorText...), avatarInputs...),
+	})
+	require.Nil(t, err)
+
+	requestData, err := coder.EncodeRequest(testName, append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...))
+	require.Nil(t, err)
+
+	status, res := serve(handler, http.MethodGet, "/"+testSender.Hex()+"/"+hexutil.Encode(requestData)+".json", nil)
+	require.Equal(t, http.StatusOK, status)
+
+	response := verifyResponse(t, signer, requestData, res["data"])
+
+	decoded, err := abi.IMulticallable.Methods["multicall"].Outputs.Unpack(response.Result)
+	require.Nil(t, err)
+
+	results := decoded[0].([][]byte)
+	require.Len(t, results, 3)
+
+	addrResult, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(results[0])
+	require.Nil(t, err)
+	require.Equal(t, testAddress, addrResult[0])
+
+	// the invalid record is left empty without failing the other calls
+	require.Empty(t, results[1])
+
+	avatarResult, err := abi.ITextResolver.Methods["text"].Outputs.Unpack(results[2])
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/avatar.png", avatarResult[0])
+}
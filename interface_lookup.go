@@ -0,0 +1,70 @@
+package coder
+
+import (
+	"bytes"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*InterfaceLookup)(nil)
+
+type InterfaceLookup struct {
+	LookupRequest
+	interfaceID [4]byte
+}
+
+func NewInterfaceLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*InterfaceLookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IInterfaceResolver.Methods["interfaceImplementer"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	interfaceID, ok := decoded[1].([4]byte) // bytes4
+	if !ok {
+		return nil, malformedABIError(`failed to decode "interfaceID" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	return &InterfaceLookup{newLookupRequest(name, node, abi.IInterfaceResolver.Methods["interfaceImplementer"], senderAddress, requestData), interfaceID}, nil
+}
+
+func (l *InterfaceLookup) Kind() Kind {
+	return KindInterface
+}
+
+// InterfaceID returns the EIP-165 interface ID being queried
+func (l *InterfaceLookup) InterfaceID() [4]byte {
+	return l.interfaceID
+}
+
+func (l *InterfaceLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if len(result) != 20 {
+		return nil, nil, errors.New("address must be 20 bytes long")
+	}
+
+	if encodedResult, err = abi.IInterfaceResolver.Methods["interfaceImplementer"].Outputs.Pack(
+		common.BytesToAddress(result), // address
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
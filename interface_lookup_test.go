@@ -0,0 +1,75 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareInterfaceLookup(t *testing.T, interfaceID [4]byte) (senderAddress common.Address, requestData []byte, lookup *InterfaceLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	interfaceInputs, err := abi.IInterfaceResolver.Methods["interfaceImplementer"].Inputs.Pack(node, interfaceID)
+	require.Nil(t, err)
+
+	interfaceCallData := make([]byte, len(interfaceInputs)+4)
+	copy(interfaceCallData, abi.IInterfaceResolver.Methods["interfaceImplementer"].ID)
+	copy(interfaceCallData[4:], interfaceInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, interfaceCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewInterfaceLookup(name, interfaceInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, interfaceID, lookup.InterfaceID())
+
+	return *sender, requestData, lookup
+}
+
+func TestInterfaceLookupEncodeResult(t *testing.T) {
+	sender, requestData, lookup := prepareInterfaceLookup(t, [4]byte{0x01, 0xff, 0xc9, 0xa7})
+
+	resultAddress, err := randomAddress()
+	require.Nil(t, err)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResult(resultAddress.Bytes(), expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.IInterfaceResolver.Methods["interfaceImplementer"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	require.Equal(t, *resultAddress, decoded[0])
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestInterfaceLookupEncodeResultInvalidAddress(t *testing.T) {
+	_, _, lookup := prepareInterfaceLookup(t, [4]byte{0x01, 0xff, 0xc9, 0xa7})
+
+	invalidAddress, err := randomBytes(19)
+	require.Nil(t, err)
+
+	resultData, hash, err := lookup.EncodeResult(invalidAddress, makeExpires())
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "address must be 20 bytes long")
+}
@@ -0,0 +1,77 @@
+// Package basex implements base-N encodings over arbitrary alphabets as used by
+// base58 and base36, where leading zero bytes are represented by the first
+// character of the alphabet.
+package basex
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+type Encoding struct {
+	alphabet string
+	base     *big.Int
+	index    [256]int
+}
+
+var (
+	Base58BTC = NewEncoding("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+	Base36    = NewEncoding("0123456789abcdefghijklmnopqrstuvwxyz")
+)
+
+func NewEncoding(alphabet string) *Encoding {
+	enc := &Encoding{alphabet: alphabet, base: big.NewInt(int64(len(alphabet)))}
+	for i := range enc.index {
+		enc.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		enc.index[alphabet[i]] = i
+	}
+	return enc
+}
+
+func (enc *Encoding) Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(b[zeros:])
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*2)
+	for n.Sign() > 0 {
+		n.DivMod(n, enc.base, mod)
+		out = append(out, enc.alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, enc.alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func (enc *Encoding) Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == enc.alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		d := enc.index[s[i]]
+		if d < 0 {
+			return nil, errors.Errorf("invalid character %q at position %d", s[i], i)
+		}
+		n.Mul(n, enc.base)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	b := n.Bytes()
+	out := make([]byte, zeros+len(b))
+	copy(out[zeros:], b)
+	return out, nil
+}
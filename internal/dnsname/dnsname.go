@@ -0,0 +1,98 @@
+// Package dnsname encodes and decodes names in the DNS wire format used by
+// ENSIP-10 resolve(bytes,bytes). Unlike DNS, labels may be up to 255 bytes
+// long, which leaves room for encoded label hashes such as "[4f5b...d7f0]".
+//
+// https://docs.ens.domains/ensip/10
+package dnsname
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maximum length of a label, limited by its one-byte length prefix
+const maxLabelLength = 255
+
+// Decode decodes a dns-encoded name, which must end with the zero-length root
+// label
+func Decode(encoded []byte) (string, error) {
+	if len(encoded) == 0 {
+		return "", errors.New("empty name")
+	}
+
+	var labels []string
+	offset := 0
+	for {
+		l := int(encoded[offset])
+		offset++
+
+		if l == 0 {
+			if offset != len(encoded) {
+				return "", errors.New("unexpected terminator")
+			}
+			break
+		}
+
+		// there must be at least one byte left for the terminator
+		if len(encoded)-offset-l < 1 {
+			return "", errors.New("out of bounds")
+		}
+
+		label := string(encoded[offset : offset+l])
+		if strings.ContainsAny(label, "\x00.") {
+			return "", errors.New("unexpected character in label")
+		}
+		labels = append(labels, label)
+		offset += l
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// Encode dns-encodes a name. Leading and trailing periods are ignored.
+func Encode(name string) ([]byte, error) {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return []byte{0}, nil
+	}
+
+	encoded := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return nil, errors.New("empty label")
+		}
+		if len(label) > maxLabelLength {
+			return nil, errors.New("label too long")
+		}
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+
+	return append(encoded, 0), nil
+}
+
+// ReadName reads an uncompressed name in a DNS resource record starting at
+// offset and returns the offset just past it. Labels are limited to 63 bytes,
+// as in DNS.
+func ReadName(data []byte, offset int) (int, error) {
+	for length := 0; ; {
+		if offset >= len(data) {
+			return 0, errors.New("name is truncated")
+		}
+
+		labelLength := int(data[offset])
+		if labelLength > 63 {
+			return 0, errors.New("compressed or invalid label")
+		}
+
+		offset += labelLength + 1
+		if length += labelLength + 1; length > 255 {
+			return 0, errors.New("name is too long")
+		}
+
+		if labelLength == 0 {
+			return offset, nil
+		}
+	}
+}
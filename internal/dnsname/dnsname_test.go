@@ -0,0 +1,87 @@
+package dnsname
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	encodedLabel := "[4f5b812789fc606be1b3b16908db13fc7a9adf7ca72641f84d75b47069d3d7f0]"
+
+	for _, tc := range []struct {
+		name    string
+		encoded []byte
+	}{
+		{"", []byte{0}},
+		{"eth", []byte("\x03eth\x00")},
+		{"test.eth", []byte("\x04test\x03eth\x00")},
+		{encodedLabel + ".eth", append(append([]byte{66}, encodedLabel...), "\x03eth\x00"...)},
+		{strings.Repeat("a", 255), append(append([]byte{255}, strings.Repeat("a", 255)...), 0)},
+	} {
+		encoded, err := Encode(tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.encoded, encoded, tc.name)
+
+		name, err := Decode(tc.encoded)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.name, name)
+	}
+
+	// leading and trailing periods are ignored
+	encoded, err := Encode(".test.eth.")
+	require.Nil(t, err)
+	require.Equal(t, []byte("\x04test\x03eth\x00"), encoded)
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	_, err := Encode("test..eth")
+	require.EqualError(t, err, "empty label")
+
+	_, err = Encode(strings.Repeat("a", 256) + ".eth")
+	require.EqualError(t, err, "label too long")
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		encoded []byte
+		err     string
+	}{
+		{nil, "empty name"},
+		{[]byte("\x03eth"), "out of bounds"},
+		{[]byte("\x04test"), "out of bounds"},
+		{[]byte("\x03eth\x00\x00"), "unexpected terminator"},
+		{[]byte("\x00\x03eth\x00"), "unexpected terminator"},
+		{[]byte("\x03e\x00h\x00"), "unexpected character in label"},
+		{[]byte("\x03e.h\x00"), "unexpected character in label"},
+	} {
+		_, err := Decode(tc.encoded)
+		require.EqualError(t, err, tc.err, "%x", tc.encoded)
+	}
+}
+
+func TestReadName(t *testing.T) {
+	data := []byte("\xff\x04test\x03eth\x00\x00\x10")
+
+	end, err := ReadName(data, 1)
+	require.Nil(t, err)
+	require.Equal(t, 11, end)
+
+	end, err = ReadName(data, 10)
+	require.Nil(t, err)
+	require.Equal(t, 11, end)
+
+	for _, tc := range []struct {
+		data []byte
+		err  string
+	}{
+		{nil, "name is truncated"},
+		{[]byte("\x04test\x03eth"), "name is truncated"},
+		{[]byte("\xc0\x0c"), "compressed or invalid label"},
+		{[]byte(strings.Repeat("\x3f"+strings.Repeat("a", 63), 4) + "\x00"), "name is too long"},
+	} {
+		_, err := ReadName(tc.data, 0)
+		require.EqualError(t, err, tc.err, "%x", tc.data)
+	}
+}
@@ -3,16 +3,143 @@ package coder
 import (
 	"encoding/binary"
 
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type Lookup interface {
 	Name() string
+	// Node returns the namehash of the name, as verified against the node in
+	// the resolver function call
+	Node() [32]byte
+	// Selector returns the selector of the resolver function being called
+	Selector() [4]byte
+	// Sender returns the address of the contract that sent the request
+	Sender() common.Address
+	// RequestData returns the resolve(bytes,bytes) calldata
+	RequestData() []byte
+	// Kind returns the kind of the lookup, KindUnknown for custom lookups
+	Kind() Kind
 	EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error)
 }
 
-func hashResult(target common.Address, expires uint64, request []byte, result []byte) []byte {
+// Kind identifies the resolver function of a built-in lookup
+type Kind int
+
+const (
+	KindUnknown       Kind = iota
+	KindAddr               // addr(bytes32)
+	KindMulticoinAddr      // addr(bytes32,uint256)
+	KindText               // text(bytes32,string)
+	KindContenthash        // contenthash(bytes32)
+	KindName               // name(bytes32)
+	KindPubkey             // pubkey(bytes32)
+	KindABI                // ABI(bytes32,uint256)
+	KindInterface          // interfaceImplementer(bytes32,bytes4)
+	KindDNSRecord          // dnsRecord(bytes32,bytes32,uint16)
+	KindMulticall          // multicall(bytes[])
+)
+
+var kindNames = map[Kind]string{
+	KindUnknown:       "unknown",
+	KindAddr:          "addr",
+	KindMulticoinAddr: "multicoinAddr",
+	KindText:          "text",
+	KindContenthash:   "contenthash",
+	KindName:          "name",
+	KindPubkey:        "pubkey",
+	KindABI:           "ABI",
+	KindInterface:     "interfaceImplementer",
+	KindDNSRecord:     "dnsRecord",
+	KindMulticall:     "multicall",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return kindNames[KindUnknown]
+}
+
+// LookupRequest holds the request common to all lookups and implements the
+// accessors of the Lookup interface, ResultHasher and UnknownLabelsLookup.
+// Custom lookups can embed it and only implement EncodeResult, hashing the
+// encoded result with ResultHash. Kind returns KindUnknown.
+type LookupRequest struct {
+	name          string
+	node          [32]byte
+	selector      [4]byte
+	senderAddress common.Address
+	requestData   []byte
+}
+
+// NewLookupRequest returns the request for a lookup of the name, whose node is
+// its namehash, decoded from the resolve(bytes,bytes) calldata sent to the
+// sender contract
+func NewLookupRequest(name string, node [32]byte, selector [4]byte, senderAddress common.Address, requestData []byte) LookupRequest {
+	return LookupRequest{name, node, selector, senderAddress, requestData}
+}
+
+func newLookupRequest(name string, node [32]byte, method ethabi.Method, senderAddress common.Address, requestData []byte) LookupRequest {
+	var selector [4]byte
+	copy(selector[:], method.ID)
+	return NewLookupRequest(name, node, selector, senderAddress, requestData)
+}
+
+func (r *LookupRequest) Name() string {
+	return r.name
+}
+
+func (r *LookupRequest) Node() [32]byte {
+	return r.node
+}
+
+func (r *LookupRequest) Selector() [4]byte {
+	return r.selector
+}
+
+func (r *LookupRequest) Sender() common.Address {
+	return r.senderAddress
+}
+
+func (r *LookupRequest) RequestData() []byte {
+	return append([]byte{}, r.requestData...)
+}
+
+func (r *LookupRequest) Kind() Kind {
+	return KindUnknown
+}
+
+func (r *LookupRequest) HasUnknownLabels() bool {
+	return namehash.HasEncodedLabels(r.name)
+}
+
+func (r *LookupRequest) ResultHash(encodedResult []byte, expires uint64) []byte {
+	return HashResult(r.senderAddress, expires, r.requestData, encodedResult)
+}
+
+// UnknownLabelsLookup is implemented by the built-in lookups. HasUnknownLabels
+// reports whether the name has encoded labels like "[4f5b...d7f0]", the label
+// hashes of labels whose preimages were unknown to the client. The record
+// should then be looked up by node rather than by name.
+type UnknownLabelsLookup interface {
+	Lookup
+	HasUnknownLabels() bool
+}
+
+// ResultHasher is implemented by lookups that support response verification
+// with DecodeResponse. ResultHash recomputes the hash that EncodeResult returns
+// for the encoded result.
+type ResultHasher interface {
+	ResultHash(encodedResult []byte, expires uint64) []byte
+}
+
+// HashResult returns the hash signed by the gateway for a response to a request
+// sent to the target contract, as computed by SignatureVerifier.makeSignatureHash.
+// Custom lookups should return it from EncodeResult.
+func HashResult(target common.Address, expires uint64, request []byte, result []byte) []byte {
 	expiresBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(expiresBytes, expires)
 
@@ -0,0 +1,26 @@
+package coder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindString(t *testing.T) {
+	require.Equal(t, "addr", KindAddr.String())
+	require.Equal(t, "multicoinAddr", KindMulticoinAddr.String())
+	require.Equal(t, "interfaceImplementer", KindInterface.String())
+	require.Equal(t, "multicall", KindMulticall.String())
+	require.Equal(t, "unknown", KindUnknown.String())
+	require.Equal(t, "unknown", Kind(100).String())
+}
+
+func TestRequestDataIsCopied(t *testing.T) {
+	_, requestData, lookup := prepareAddrLookup(t)
+
+	data := lookup.RequestData()
+	require.Equal(t, requestData, data)
+
+	data[0] ^= 0xff
+	require.Equal(t, requestData, lookup.RequestData())
+}
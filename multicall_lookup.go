@@ -0,0 +1,134 @@
+package coder
+
+import (
+	"bytes"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*MulticallLookup)(nil)
+
+// MulticallLookup is a batch of lookups on the same name, sent by ENSIP-10
+// clients as resolve(name, multicall(bytes[]))
+type MulticallLookup struct {
+	LookupRequest
+	calls []MulticallCall
+}
+
+// MulticallCall is a single call in a multicall batch. If the call could not
+// be decoded, Lookup is nil and Err holds the reason.
+type MulticallCall struct {
+	CallData []byte
+	Lookup   Lookup
+	Err      error
+}
+
+// NewMulticallLookup decodes the calls in the batch using DefaultRegistry
+func NewMulticallLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*MulticallLookup, error) {
+	return newMulticallLookup(DefaultRegistry, name, lookupInputs, senderAddress, requestData)
+}
+
+func newMulticallLookup(registry *Registry, name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*MulticallLookup, error) {
+	node, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IMulticallable.Methods["multicall"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	data, ok := decoded[0].([][]byte) // bytes[]
+	if !ok {
+		return nil, malformedABIError(`failed to decode "data" in lookup inputs`)
+	}
+
+	calls := make([]MulticallCall, len(data))
+	for i, callData := range data {
+		calls[i].CallData = callData
+
+		if len(callData) >= 4 && bytes.Equal(callData[0:4], abi.SelectorMulticall) {
+			calls[i].Err = errors.New("nested multicall is not supported")
+			continue
+		}
+
+		calls[i].Lookup, calls[i].Err = registry.decodeLookup(name, callData, senderAddress, requestData)
+	}
+
+	return &MulticallLookup{newLookupRequest(name, node, abi.IMulticallable.Methods["multicall"], senderAddress, requestData), calls}, nil
+}
+
+func (l *MulticallLookup) Kind() Kind {
+	return KindMulticall
+}
+
+// Calls returns the decoded calls in the order they appear in the batch
+func (l *MulticallLookup) Calls() []MulticallCall {
+	calls := make([]MulticallCall, len(l.calls))
+	copy(calls, l.calls)
+	return calls
+}
+
+// EncodeResults encodes one result per call using the lookup of each call and
+// returns the encoded multicall result and its hash. Results for calls that
+// failed to decode must be nil. A nil result is returned as empty bytes, which
+// clients treat as a failed call.
+func (l *MulticallLookup) EncodeResults(results [][]byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if len(results) != len(l.calls) {
+		return nil, nil, errors.Errorf("expected %d results, got %d", len(l.calls), len(results))
+	}
+
+	encodedResults := make([][]byte, len(results))
+	for i, call := range l.calls {
+		if call.Lookup == nil {
+			if results[i] != nil {
+				return nil, nil, errors.Errorf("unexpected result for failed call %d", i)
+			}
+			encodedResults[i] = []byte{}
+			continue
+		}
+
+		if results[i] == nil {
+			encodedResults[i] = []byte{}
+			continue
+		}
+
+		if encodedResults[i], _, err = call.Lookup.EncodeResult(results[i], expires); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to encode result for call %d", i)
+		}
+	}
+
+	return l.encodeResults(encodedResults, expires)
+}
+
+// EncodeResult takes the ABI-encoded bytes[] of already encoded call results,
+// in the same format as the encodedResult returned by EncodeResults.
+func (l *MulticallLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	decoded, err := abi.IMulticallable.Methods["multicall"].Outputs.Unpack(result)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "result must be an ABI-encoded bytes[]")
+	}
+
+	results, ok := decoded[0].([][]byte) // bytes[]
+	if !ok || len(results) != len(l.calls) {
+		return nil, nil, errors.Errorf("expected %d results", len(l.calls))
+	}
+
+	return l.encodeResults(results, expires)
+}
+
+func (l *MulticallLookup) encodeResults(results [][]byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if encodedResult, err = abi.IMulticallable.Methods["multicall"].Outputs.Pack(
+		results, // bytes[]
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
@@ -0,0 +1,142 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareMulticallLookup(t *testing.T) (senderAddress common.Address, requestData []byte, lookup *MulticallLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+	addrCallData := append(append([]byte{}, abi.SelectorAddr...), addrInputs...)
+
+	textInputs, err := abi.ITextResolver.Methods["text"].Inputs.Pack(node, "avatar")
+	require.Nil(t, err)
+	textCallData := append(append([]byte{}, abi.SelectorText...), textInputs...)
+
+	unsupportedCallData := append([]byte{0xde, 0xad, 0xbe, 0xef}, addrInputs...)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack(
+		[][]byte{addrCallData, textCallData, unsupportedCallData, {0x01}},
+	)
+	require.Nil(t, err)
+	multicallCallData := append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, multicallCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewMulticallLookup(name, multicallInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+
+	return *sender, requestData, lookup
+}
+
+func TestMulticallLookupCalls(t *testing.T) {
+	_, _, lookup := prepareMulticallLookup(t)
+
+	require.Equal(t, KindMulticall, lookup.Kind())
+
+	calls := lookup.Calls()
+	require.Len(t, calls, 4)
+
+	require.Nil(t, calls[0].Err)
+	require.IsType(t, &AddrLookup{}, calls[0].Lookup)
+	require.Equal(t, KindAddr, calls[0].Lookup.Kind())
+	require.Equal(t, lookup.Node(), calls[0].Lookup.Node())
+
+	require.Nil(t, calls[1].Err)
+	textLookup, ok := calls[1].Lookup.(*TextLookup)
+	require.True(t, ok, "expected the second call to be a TextLookup")
+	require.Equal(t, "avatar", textLookup.Key())
+
+	require.Nil(t, calls[2].Lookup)
+	require.EqualError(t, calls[2].Err, "unsupported lookup: 0xdeadbeef")
+
+	require.Nil(t, calls[3].Lookup)
+	require.EqualError(t, calls[3].Err, "call data is too short")
+}
+
+func TestMulticallLookupEncodeResults(t *testing.T) {
+	sender, requestData, lookup := prepareMulticallLookup(t)
+
+	resultAddress, err := randomAddress()
+	require.Nil(t, err)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResults(
+		[][]byte{resultAddress.Bytes(), []byte("https://example.com/avatar.png"), nil, nil},
+		expires,
+	)
+	require.Nil(t, err)
+
+	decoded, err := abi.IMulticallable.Methods["multicall"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	results := decoded[0].([][]byte)
+	require.Len(t, results, 4)
+
+	addrResult, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(results[0])
+	require.Nil(t, err)
+	require.Equal(t, *resultAddress, addrResult[0])
+
+	textResult, err := abi.ITextResolver.Methods["text"].Outputs.Unpack(results[1])
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com/avatar.png", textResult[0])
+
+	require.Empty(t, results[2])
+	require.Empty(t, results[3])
+
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+
+	// EncodeResult accepts the encoded results as-is
+	reencoded, rehash, err := lookup.EncodeResult(resultData, expires)
+	require.Nil(t, err)
+	require.Equal(t, resultData, reencoded)
+	require.Equal(t, hash, rehash)
+}
+
+func TestMulticallLookupEncodeResultsInvalid(t *testing.T) {
+	_, _, lookup := prepareMulticallLookup(t)
+
+	resultAddress, err := randomAddress()
+	require.Nil(t, err)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResults([][]byte{resultAddress.Bytes()}, expires)
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "expected 4 results, got 1")
+
+	resultData, hash, err = lookup.EncodeResults([][]byte{{0x01}, {}, nil, nil}, expires)
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "failed to encode result for call 0: address must be 20 bytes long")
+
+	resultData, hash, err = lookup.EncodeResults([][]byte{resultAddress.Bytes(), {}, {0x01}, nil}, expires)
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "unexpected result for failed call 2")
+}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/coins"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -13,42 +14,40 @@ import (
 var _ Lookup = (*MulticoinAddrLookup)(nil)
 
 type MulticoinAddrLookup struct {
-	name          string
-	senderAddress common.Address
-	requestData   []byte
-	coinType      *big.Int
+	LookupRequest
+	coinType *big.Int
 }
 
 func NewMulticoinAddrLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*MulticoinAddrLookup, error) {
 	nh, err := namehash.NameHash(name)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get namehash")
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
 	}
 
 	decoded, err := abi.IMulticoinAddrResolver.Methods["addr"].Inputs.Unpack(lookupInputs)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode lookup inputs")
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
 	}
 
 	node, ok := decoded[0].([32]byte) // bytes32
 	if !ok {
-		return nil, errors.New(`failed to decode "node" in lookup inputs`)
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
 	}
 
 	coinType, ok := decoded[1].(*big.Int) // uint256
 	if !ok {
-		return nil, errors.New(`failed to decode "coinType" in lookup inputs`)
+		return nil, malformedABIError(`failed to decode "coinType" in lookup inputs`)
 	}
 
 	if !bytes.Equal(node[:], nh[:]) {
-		return nil, errors.New("name hash does not match the lookup input")
+		return nil, ErrNodeMismatch
 	}
 
-	return &MulticoinAddrLookup{name, senderAddress, requestData, coinType}, nil
+	return &MulticoinAddrLookup{newLookupRequest(name, node, abi.IMulticoinAddrResolver.Methods["addr"], senderAddress, requestData), coinType}, nil
 }
 
-func (l *MulticoinAddrLookup) Name() string {
-	return l.name
+func (l *MulticoinAddrLookup) Kind() Kind {
+	return KindMulticoinAddr
 }
 
 func (l *MulticoinAddrLookup) CoinType() *big.Int {
@@ -56,14 +55,58 @@ func (l *MulticoinAddrLookup) CoinType() *big.Int {
 	return bi.Add(l.coinType, bi)
 }
 
+// IsEVM reports whether the lookup is for an EVM address: coin type 60, the
+// ENSIP-11 coin type of an EVM chain or the ENSIP-19 default EVM coin type
+func (l *MulticoinAddrLookup) IsEVM() bool {
+	return coins.IsEVMCoinType(l.coinType)
+}
+
+// IsDefaultEVM reports whether the lookup is for the default EVM address, used
+// on chains that have no address of their own
+func (l *MulticoinAddrLookup) IsDefaultEVM() bool {
+	return coins.IsDefaultEVMCoinType(l.coinType)
+}
+
+// ChainID returns the ID of the EVM chain of the coin type, 1 for coin type 60.
+// ok is false for the default EVM coin type and non-EVM coin types.
+func (l *MulticoinAddrLookup) ChainID() (chainID uint64, ok bool) {
+	return coins.EVMChainID(l.coinType)
+}
+
+// EncodeResult encodes the binary form of the address. For EVM coin types, the
+// result must be a 20-byte address, or empty if no address is set.
 func (l *MulticoinAddrLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if l.IsEVM() && len(result) != 0 && len(result) != 20 {
+		return nil, nil, errors.New("address must be 20 bytes long")
+	}
+
 	if encodedResult, err = abi.IMulticoinAddrResolver.Methods["addr"].Outputs.Pack(
 		result, // bytes
 	); err != nil {
 		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
 	}
 
-	hash = hashResult(l.senderAddress, expires, l.requestData, encodedResult)
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
 
 	return encodedResult, hash, nil
 }
+
+// ParseAddress converts a human-readable address to the binary form for the
+// coin type of the lookup, using the codecs in coins.DefaultRegistry. Invalid
+// addresses are rejected, and coins.ErrUnsupportedCoinType is returned if there
+// is no codec for the coin type.
+func (l *MulticoinAddrLookup) ParseAddress(addr string) ([]byte, error) {
+	return coins.Parse(l.coinType, addr)
+}
+
+// EncodeTextResult is like EncodeResult, but takes the human-readable address.
+// An empty address encodes an empty result, meaning no address is set.
+func (l *MulticoinAddrLookup) EncodeTextResult(addr string, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	var result []byte
+	if addr != "" {
+		if result, err = l.ParseAddress(addr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return l.EncodeResult(result, expires)
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/coins"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
 	"github.com/ethereum/go-ethereum/common"
-	dnsname "github.com/petejkim/ens-dnsname"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +23,11 @@ func prepareMulticoinAddrLookup(t *testing.T) (senderAddress common.Address, req
 	node, err := namehash.NameHash(name)
 	require.Nil(t, err)
 
+	// a non-EVM coin type, so that any result can be encoded
 	coinType := big.NewInt(int64(mathrand.Intn(100000)))
+	if coinType.Int64() == coins.CoinTypeETH {
+		coinType.SetInt64(coins.CoinTypeBTC)
+	}
 
 	multicoinAddrInputs, err := abi.IMulticoinAddrResolver.Methods["addr"].Inputs.Pack(node, coinType)
 	require.Nil(t, err)
@@ -63,5 +69,104 @@ func TestMulticoinAddrLookupEncodeResult(t *testing.T) {
 	require.Nil(t, err)
 
 	require.Equal(t, result, decoded[0])
-	require.Equal(t, hashResult(sender, expires, requestData, resultData), hash)
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestMulticoinAddrLookupEncodeTextResult(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	requestData, err := EncodeMulticoinAddrRequest(randomName(), big.NewInt(coins.CoinTypeBTC))
+	require.Nil(t, err)
+
+	lookup, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+	mcLookup := lookup.(*MulticoinAddrLookup)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := mcLookup.EncodeTextResult("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.IMulticoinAddrResolver.Methods["addr"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0x0014751e76e8199196d454941c45d1b3a323f1433bd6"), decoded[0])
+	require.Equal(t, HashResult(*sender, expires, requestData, resultData), hash)
+
+	// no address set
+	resultData, _, err = mcLookup.EncodeTextResult("", expires)
+	require.Nil(t, err)
+	decoded, err = abi.IMulticoinAddrResolver.Methods["addr"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+	require.Empty(t, decoded[0])
+
+	// typo in the checksum
+	resultData, hash, err = mcLookup.EncodeTextResult("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", expires)
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "invalid address for coin type 0: invalid bech32 checksum")
+
+	// an ethereum address for bitcoin
+	_, _, err = mcLookup.EncodeTextResult("0x314159265dD8dbb310642f98f50C066173C1259b", expires)
+	require.NotNil(t, err)
+}
+
+func TestMulticoinAddrLookupEncodeTextResultUnsupportedCoinType(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	requestData, err := EncodeMulticoinAddrRequest(randomName(), big.NewInt(999999))
+	require.Nil(t, err)
+
+	lookup, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+
+	_, _, err = lookup.(*MulticoinAddrLookup).EncodeTextResult("anything", 0)
+	require.Equal(t, coins.ErrUnsupportedCoinType, err)
+}
+
+func TestMulticoinAddrLookupEVM(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	resultAddress, err := randomAddress()
+	require.Nil(t, err)
+
+	for _, tc := range []struct {
+		coinType  int64
+		evm       bool
+		isDefault bool
+		chainID   uint64
+	}{
+		{coins.CoinTypeBTC, false, false, 0},
+		{coins.CoinTypeETH, true, false, 1},
+		{coins.CoinTypeDefaultEVM, true, true, 0},
+		{0x8000000a, true, false, 10},
+	} {
+		requestData, err := EncodeMulticoinAddrRequest(randomName(), big.NewInt(tc.coinType))
+		require.Nil(t, err)
+
+		lookup, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+		require.Nil(t, err)
+		mcLookup := lookup.(*MulticoinAddrLookup)
+
+		require.Equal(t, tc.evm, mcLookup.IsEVM())
+		require.Equal(t, tc.isDefault, mcLookup.IsDefaultEVM())
+		chainID, ok := mcLookup.ChainID()
+		require.Equal(t, tc.chainID != 0, ok)
+		require.Equal(t, tc.chainID, chainID)
+
+		_, _, err = mcLookup.EncodeResult(resultAddress.Bytes(), 0)
+		require.Nil(t, err)
+
+		_, _, err = mcLookup.EncodeResult(nil, 0)
+		require.Nil(t, err)
+
+		_, _, err = mcLookup.EncodeResult(make([]byte, 32), 0)
+		if tc.evm {
+			require.EqualError(t, err, "address must be 20 bytes long")
+		} else {
+			require.Nil(t, err)
+		}
+	}
 }
@@ -0,0 +1,125 @@
+package coder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/coins"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*NameLookup)(nil)
+
+type NameLookup struct {
+	LookupRequest
+	reverseAddress  []byte
+	reverseCoinType *big.Int
+}
+
+func NewNameLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*NameLookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.INameResolver.Methods["name"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	reverseAddress, reverseCoinType, _ := parseReverseName(name)
+
+	return &NameLookup{newLookupRequest(name, node, abi.INameResolver.Methods["name"], senderAddress, requestData), reverseAddress, reverseCoinType}, nil
+}
+
+func (l *NameLookup) Kind() Kind {
+	return KindName
+}
+
+// IsReverse returns whether the name is a reverse name, either
+// "<address>.addr.reverse" or ENSIP-19 "<address>.<coinType>.reverse"
+func (l *NameLookup) IsReverse() bool {
+	return l.reverseAddress != nil
+}
+
+// ReverseAddress returns the address encoded in the reverse name, or nil if
+// the name is not a reverse name
+func (l *NameLookup) ReverseAddress() []byte {
+	if l.reverseAddress == nil {
+		return nil
+	}
+	return append([]byte{}, l.reverseAddress...)
+}
+
+// ReverseCoinType returns the coin type of the reverse namespace, which is 60
+// for addr.reverse and 0x80000000 for default.reverse, or nil if the name is
+// not a reverse name
+func (l *NameLookup) ReverseCoinType() *big.Int {
+	if l.reverseCoinType == nil {
+		return nil
+	}
+	return new(big.Int).Set(l.reverseCoinType)
+}
+
+func (l *NameLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if encodedResult, err = abi.INameResolver.Methods["name"].Outputs.Pack(
+		string(result), // string
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
+
+// parseReverseName extracts the address and coin type from a reverse name
+// https://docs.ens.domains/ensip/19
+func parseReverseName(name string) (address []byte, coinType *big.Int, ok bool) {
+	labels := strings.Split(strings.ToLower(name), ".")
+	if len(labels) != 3 || labels[2] != "reverse" {
+		return nil, nil, false
+	}
+
+	switch ns := labels[1]; ns {
+	case "addr":
+		coinType = big.NewInt(coins.CoinTypeETH)
+	case "default":
+		coinType = big.NewInt(coins.CoinTypeDefaultEVM)
+	default:
+		// the coin type is lowercase hex without leading zeros
+		if len(ns) == 0 || (len(ns) > 1 && ns[0] == '0') || strings.Trim(ns, "0123456789abcdef") != "" {
+			return nil, nil, false
+		}
+		var valid bool
+		if coinType, valid = new(big.Int).SetString(ns, 16); !valid {
+			return nil, nil, false
+		}
+	}
+
+	address, err := hex.DecodeString(labels[0])
+	if err != nil || len(address) == 0 {
+		return nil, nil, false
+	}
+
+	// EVM addresses are always 20 bytes long
+	if coins.IsEVMCoinType(coinType) && len(address) != 20 {
+		return nil, nil, false
+	}
+
+	return address, coinType, true
+}
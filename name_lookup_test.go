@@ -0,0 +1,121 @@
+package coder
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareNameLookup(t *testing.T, name string) (senderAddress common.Address, requestData []byte, lookup *NameLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	nameInputs, err := abi.INameResolver.Methods["name"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	nameCallData := make([]byte, len(nameInputs)+4)
+	copy(nameCallData, abi.INameResolver.Methods["name"].ID)
+	copy(nameCallData[4:], nameInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, nameCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewNameLookup(name, nameInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+
+	return *sender, requestData, lookup
+}
+
+func TestNameLookupEncodeResult(t *testing.T) {
+	address, err := randomAddress()
+	require.Nil(t, err)
+
+	sender, requestData, lookup := prepareNameLookup(t, hexutil.Encode(address.Bytes())[2:]+".addr.reverse")
+
+	result := []byte(randomName())
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResult(result, expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.INameResolver.Methods["name"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	require.Equal(t, string(result), decoded[0])
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestNameLookupReverseAddress(t *testing.T) {
+	address, err := randomAddress()
+	require.Nil(t, err)
+	hexAddress := hexutil.Encode(address.Bytes())[2:]
+
+	_, _, lookup := prepareNameLookup(t, hexAddress+".addr.reverse")
+	require.True(t, lookup.IsReverse())
+	require.Equal(t, address.Bytes(), lookup.ReverseAddress())
+	require.Equal(t, big.NewInt(60), lookup.ReverseCoinType())
+
+	_, _, lookup = prepareNameLookup(t, hexAddress+".80002105.reverse")
+	require.True(t, lookup.IsReverse())
+	require.Equal(t, address.Bytes(), lookup.ReverseAddress())
+	require.Equal(t, big.NewInt(0x80002105), lookup.ReverseCoinType())
+
+	_, _, lookup = prepareNameLookup(t, hexAddress+".default.reverse")
+	require.True(t, lookup.IsReverse())
+	require.Equal(t, big.NewInt(0x80000000), lookup.ReverseCoinType())
+
+	_, _, lookup = prepareNameLookup(t, "0014c0ffee.0.reverse")
+	require.True(t, lookup.IsReverse())
+	require.Equal(t, []byte{0x00, 0x14, 0xc0, 0xff, 0xee}, lookup.ReverseAddress())
+	require.Equal(t, big.NewInt(0), lookup.ReverseCoinType())
+
+	_, _, lookup = prepareNameLookup(t, "0014c0ffee.01f5.reverse")
+	require.False(t, lookup.IsReverse(), "coin type with a leading zero is not canonical")
+
+	_, _, lookup = prepareNameLookup(t, "0014c0ffee.00.reverse")
+	require.False(t, lookup.IsReverse(), "coin type with a leading zero is not canonical")
+
+	_, _, lookup = prepareNameLookup(t, hexAddress+".-3c.reverse")
+	require.False(t, lookup.IsReverse(), "coin type with a sign is not canonical")
+
+	// "+" is not a valid character in a name, so parse it directly
+	_, _, ok := parseReverseName(hexAddress + ".+3c.reverse")
+	require.False(t, ok, "coin type with a sign is not canonical")
+
+	// non-EVM coin types may have addresses of any length
+	_, _, lookup = prepareNameLookup(t, "0014c0ffee.1f5.reverse")
+	require.True(t, lookup.IsReverse())
+	require.Equal(t, []byte{0x00, 0x14, 0xc0, 0xff, 0xee}, lookup.ReverseAddress())
+	require.Equal(t, big.NewInt(501), lookup.ReverseCoinType())
+
+	for _, name := range []string{
+		randomName(),
+		"addr.reverse",
+		"c0ffee.addr.reverse",
+		"zz" + hexAddress[2:] + ".addr.reverse",
+		hexAddress + ".addr.reverse.eth",
+	} {
+		_, _, lookup = prepareNameLookup(t, name)
+		require.False(t, lookup.IsReverse(), name)
+		require.Nil(t, lookup.ReverseAddress())
+		require.Nil(t, lookup.ReverseCoinType())
+	}
+}
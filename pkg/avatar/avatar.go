@@ -0,0 +1,222 @@
+// Package avatar parses and formats the URIs of ENS avatar text records.
+//
+// https://docs.ens.domains/ensip/12
+package avatar
+
+import (
+	"encoding/base64"
+	"math/big"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/contenthash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Avatar is a parsed avatar URI: *NFT, *IPFS, *HTTP or *Data
+type Avatar interface {
+	// String returns the avatar URI
+	String() string
+}
+
+var (
+	_ Avatar = (*NFT)(nil)
+	_ Avatar = (*IPFS)(nil)
+	_ Avatar = (*HTTP)(nil)
+	_ Avatar = (*Data)(nil)
+)
+
+// Standard is the token standard of an NFT avatar
+type Standard string
+
+const (
+	StandardERC721  Standard = "erc721"
+	StandardERC1155 Standard = "erc1155"
+)
+
+// NFT is a reference to a token owned by the name's owner, in the form
+// eip155:{chainId}/{erc721|erc1155}:{contract}/{tokenId}
+type NFT struct {
+	ChainID  uint64
+	Standard Standard
+	Contract common.Address
+	TokenID  *big.Int
+}
+
+func (n *NFT) String() string {
+	return "eip155:" + strconv.FormatUint(n.ChainID, 10) + "/" + string(n.Standard) + ":" +
+		strings.ToLower(n.Contract.Hex()) + "/" + n.TokenID.String()
+}
+
+// IPFS is an ipfs:// URI. CID is the binary CIDv1, and Path is the path within
+// it, if any, starting with a slash.
+type IPFS struct {
+	CID  []byte
+	Path string
+}
+
+// String formats the CID as base32 CIDv1
+func (i *IPFS) String() string {
+	return (&contenthash.ContentHash{Codec: contenthash.CodecIPFS, Content: i.CID}).String() + i.Path
+}
+
+// GatewayURL returns the URL of the content on an IPFS HTTP gateway such as
+// "https://ipfs.io"
+func (i *IPFS) GatewayURL(gateway string) string {
+	return strings.TrimSuffix(gateway, "/") + "/ipfs/" + strings.TrimPrefix(i.String(), "ipfs://")
+}
+
+// HTTP is an http:// or https:// URL
+type HTTP struct {
+	URL *url.URL
+}
+
+func (h *HTTP) String() string {
+	return h.URL.String()
+}
+
+// Data is an image embedded in a data: URI
+type Data struct {
+	// MediaType is the media type of the image with its parameters, e.g.
+	// "image/svg+xml;charset=utf-8"
+	MediaType string
+	// Base64 is whether the URI is base64-encoded rather than percent-encoded
+	Base64 bool
+	Data   []byte
+}
+
+func (d *Data) String() string {
+	if d.Base64 {
+		return "data:" + d.MediaType + ";base64," + base64.StdEncoding.EncodeToString(d.Data)
+	}
+	return "data:" + d.MediaType + "," + url.PathEscape(string(d.Data))
+}
+
+// Parse parses an avatar URI
+func Parse(uri string) (Avatar, error) {
+	i := strings.IndexByte(uri, ':')
+	if i < 0 {
+		return nil, errors.New("avatar must be a URI")
+	}
+
+	switch scheme := strings.ToLower(uri[:i]); scheme {
+	case "eip155":
+		return parseNFT(uri[i+1:])
+	case "ipfs":
+		return parseIPFS(uri)
+	case "http", "https":
+		u, err := url.Parse(uri)
+		if err != nil || u.Host == "" {
+			return nil, errors.New("invalid avatar URL")
+		}
+		return &HTTP{u}, nil
+	case "data":
+		return parseData(uri[i+1:])
+	default:
+		return nil, errors.Errorf("unsupported avatar URI scheme: %s", scheme)
+	}
+}
+
+// Validate checks that the avatar URI is well-formed
+func Validate(uri string) error {
+	_, err := Parse(uri)
+	return err
+}
+
+func parseNFT(s string) (*NFT, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return nil, errors.New("NFT avatar must be in the form eip155:{chainId}/{standard}:{contract}/{tokenId}")
+	}
+
+	chainID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || chainID == 0 || parts[0][0] == '0' {
+		return nil, errors.New("invalid chain ID in NFT avatar")
+	}
+
+	i := strings.IndexByte(parts[1], ':')
+	if i < 0 {
+		return nil, errors.New("NFT avatar must be in the form eip155:{chainId}/{standard}:{contract}/{tokenId}")
+	}
+
+	standard := Standard(strings.ToLower(parts[1][:i]))
+	if standard != StandardERC721 && standard != StandardERC1155 {
+		return nil, errors.Errorf("unsupported NFT standard: %s", parts[1][:i])
+	}
+
+	contract := parts[1][i+1:]
+	if !strings.HasPrefix(contract, "0x") || len(contract) != 42 || !common.IsHexAddress(contract) {
+		return nil, errors.New("invalid contract address in NFT avatar")
+	}
+
+	tokenID, ok := new(big.Int).SetString(parts[2], 10)
+	if !ok || !isDigits(parts[2]) || tokenID.BitLen() > 256 {
+		return nil, errors.New("invalid token ID in NFT avatar")
+	}
+
+	return &NFT{chainID, standard, common.HexToAddress(contract), tokenID}, nil
+}
+
+func parseIPFS(uri string) (*IPFS, error) {
+	if !strings.HasPrefix(strings.ToLower(uri), "ipfs://") {
+		return nil, errors.New("IPFS avatar must be in the form ipfs://{cid}")
+	}
+	// ipfs://ipfs/{cid} is a common mistake
+	value := strings.TrimPrefix(uri[len("ipfs://"):], "ipfs/")
+
+	var path string
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		value, path = value[:i], value[i:]
+	}
+
+	ch, err := contenthash.Parse("ipfs://" + value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid IPFS avatar")
+	}
+	return &IPFS{ch.Content, path}, nil
+}
+
+func parseData(s string) (*Data, error) {
+	i := strings.IndexByte(s, ',')
+	if i < 0 {
+		return nil, errors.New("data URI must be in the form data:{mediatype}[;base64],{data}")
+	}
+	mediaType, payload := s[:i], s[i+1:]
+
+	d := &Data{}
+	if strings.HasSuffix(strings.ToLower(mediaType), ";base64") {
+		d.Base64 = true
+		mediaType = mediaType[:len(mediaType)-len(";base64")]
+	}
+
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil || !strings.HasPrefix(mt, "image/") {
+		return nil, errors.New("data URI must have an image media type")
+	}
+	d.MediaType = mediaType
+
+	if d.Base64 {
+		d.Data, err = base64.StdEncoding.DecodeString(payload)
+	} else {
+		var unescaped string
+		unescaped, err = url.PathUnescape(payload)
+		d.Data = []byte(unescaped)
+	}
+	if err != nil {
+		return nil, errors.New("invalid data URI encoding")
+	}
+
+	return d, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
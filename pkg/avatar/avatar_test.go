@@ -0,0 +1,128 @@
+package avatar
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNFT(t *testing.T) {
+	// https://docs.ens.domains/ensip/12
+	for _, tc := range []struct {
+		uri       string
+		nft       *NFT
+		formatted string
+	}{
+		{
+			"eip155:1/erc1155:0xfaafdc07907ff5120a76b34b731b278c38d6043c/1",
+			&NFT{1, StandardERC1155, common.HexToAddress("0xfaafdc07907ff5120a76b34b731b278c38d6043c"), big.NewInt(1)},
+			"",
+		},
+		{
+			"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/2430",
+			&NFT{1, StandardERC721, common.HexToAddress("0xb7f7f6c52f2e2fdb1963eab30438024864c313f6"), big.NewInt(2430)},
+			"",
+		},
+		{
+			"eip155:10/ERC721:0xB7F7F6C52F2E2FDB1963EAB30438024864C313F6/2430",
+			&NFT{10, StandardERC721, common.HexToAddress("0xb7f7f6c52f2e2fdb1963eab30438024864c313f6"), big.NewInt(2430)},
+			"eip155:10/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/2430",
+		},
+	} {
+		a, err := Parse(tc.uri)
+		require.Nil(t, err, tc.uri)
+		require.Equal(t, tc.nft, a)
+
+		formatted := tc.formatted
+		if formatted == "" {
+			formatted = tc.uri
+		}
+		require.Equal(t, formatted, a.String())
+	}
+}
+
+func TestParseIPFS(t *testing.T) {
+	for _, uri := range []string{
+		"ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4",
+		"ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4",
+		"ipfs://ipfs/QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4",
+	} {
+		a, err := Parse(uri)
+		require.Nil(t, err, uri)
+
+		i, ok := a.(*IPFS)
+		require.True(t, ok)
+		require.Equal(t, "0x0170122029f2d17be6139079dc48696d1f582a8530eb9805b561eda517e22a892c7e3f1f", hexutil.Encode(i.CID))
+		require.Empty(t, i.Path)
+		require.Equal(t, "ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4", a.String())
+	}
+
+	a, err := Parse("ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4/avatar.png")
+	require.Nil(t, err)
+	require.Equal(t, "/avatar.png", a.(*IPFS).Path)
+	require.Equal(t, "ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4/avatar.png", a.String())
+	require.Equal(t, "https://ipfs.io/ipfs/bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4/avatar.png", a.(*IPFS).GatewayURL("https://ipfs.io/"))
+}
+
+func TestParseHTTP(t *testing.T) {
+	a, err := Parse("https://example.com/avatar.png?size=100")
+	require.Nil(t, err)
+
+	h, ok := a.(*HTTP)
+	require.True(t, ok)
+	require.Equal(t, "example.com", h.URL.Host)
+	require.Equal(t, "https://example.com/avatar.png?size=100", a.String())
+}
+
+func TestParseData(t *testing.T) {
+	a, err := Parse("data:image/png;base64,iVBORw0KGgo=")
+	require.Nil(t, err)
+	require.Equal(t, &Data{"image/png", true, []byte("\x89PNG\r\n\x1a\n")}, a)
+	require.Equal(t, "data:image/png;base64,iVBORw0KGgo=", a.String())
+
+	a, err = Parse("data:image/svg+xml;charset=utf-8,%3Csvg%3E%3C%2Fsvg%3E")
+	require.Nil(t, err)
+	require.Equal(t, &Data{"image/svg+xml;charset=utf-8", false, []byte("<svg></svg>")}, a)
+
+	// round trip
+	b, err := Parse(a.String())
+	require.Nil(t, err)
+	require.Equal(t, a, b)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{"avatar.png", "avatar must be a URI"},
+		{"ftp://example.com/avatar.png", "unsupported avatar URI scheme: ftp"},
+		{"https:///avatar.png", "invalid avatar URL"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6", "NFT avatar must be in the form"},
+		{"eip155:0/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/1", "invalid chain ID in NFT avatar"},
+		{"eip155:01/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/1", "invalid chain ID in NFT avatar"},
+		{"eip155:1/erc20:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/1", "unsupported NFT standard: erc20"},
+		{"eip155:1/erc721/0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/1", "NFT avatar must be in the form"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313/1", "invalid contract address in NFT avatar"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/-1", "invalid token ID in NFT avatar"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/+1", "invalid token ID in NFT avatar"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/0x01", "invalid token ID in NFT avatar"},
+		{"eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/1" + strings.Repeat("0", 78), "invalid token ID in NFT avatar"},
+		{"ipfs:Qm", "IPFS avatar must be in the form ipfs://{cid}"},
+		{"ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD", "invalid IPFS avatar"},
+		{"data:image/png;base64", "data URI must be in the form"},
+		{"data:text/html,<script></script>", "data URI must have an image media type"},
+		{"data:image/png;base64,!!!", "invalid data URI encoding"},
+		{"data:image/svg+xml,%zz", "invalid data URI encoding"},
+	} {
+		a, err := Parse(tc.uri)
+		require.Nil(t, a, tc.uri)
+		require.NotNil(t, err, tc.uri)
+		require.Contains(t, err.Error(), tc.err, tc.uri)
+		require.EqualError(t, Validate(tc.uri), err.Error())
+	}
+}
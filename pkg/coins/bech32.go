@@ -0,0 +1,177 @@
+package coins
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// bech32 checksum variants
+// https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+type bech32Variant uint32
+
+const (
+	bech32  bech32Variant = 1
+	bech32m bech32Variant = 0x2bc830a3
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32Decode decodes a bech32 or bech32m string into its lowercase
+// human-readable part and 5-bit data, without the checksum
+func bech32Decode(s string) (hrp string, data []byte, variant bech32Variant, err error) {
+	if len(s) < 8 || len(s) > 90 {
+		return "", nil, 0, errors.New("bech32 string must be 8 to 90 characters long")
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("bech32 string must not be mixed case")
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return "", nil, 0, errors.New("invalid character in bech32 string")
+		}
+	}
+
+	pos := strings.LastIndexByte(lower, '1')
+	if pos < 1 || pos+7 > len(lower) {
+		return "", nil, 0, errors.New("invalid bech32 separator position")
+	}
+
+	hrp = lower[:pos]
+	data = make([]byte, 0, len(lower)-pos-1)
+	for i := pos + 1; i < len(lower); i++ {
+		v := strings.IndexByte(bech32Charset, lower[i])
+		if v < 0 {
+			return "", nil, 0, errors.New("invalid character in bech32 data")
+		}
+		data = append(data, byte(v))
+	}
+
+	switch variant = bech32Variant(bech32Polymod(append(bech32HRPExpand(hrp), data...))); variant {
+	case bech32, bech32m:
+	default:
+		return "", nil, 0, errors.New("invalid bech32 checksum")
+	}
+
+	return hrp, data[:len(data)-6], variant, nil
+}
+
+func bech32Encode(hrp string, data []byte, variant bech32Variant) string {
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ uint32(variant)
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range data {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// convertBits regroups data from groups of fromBits to groups of toBits. When
+// converting to 8-bit groups, padding must be zero and shorter than a group.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		out  []byte
+	)
+	maxv := uint32(1)<<toBits - 1
+	for _, v := range data {
+		if v>>fromBits != 0 {
+			return nil, errors.New("invalid data range")
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
+
+// bech32Codec is for coins whose addresses are the bech32 encoding of the raw
+// address bytes, like Cosmos
+type bech32Codec struct {
+	hrp string
+}
+
+func (c *bech32Codec) Parse(addr string) ([]byte, error) {
+	hrp, data, variant, err := bech32Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != c.hrp {
+		return nil, errors.Errorf("address must start with %q", c.hrp+"1")
+	}
+	if variant != bech32 {
+		return nil, errors.New("address must use the bech32 checksum")
+	}
+
+	b, err := convertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("address is empty")
+	}
+	return b, nil
+}
+
+func (c *bech32Codec) Format(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("address is empty")
+	}
+
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	if len(c.hrp)+1+len(values)+6 > 90 {
+		return "", errors.New("address is too long")
+	}
+	return bech32Encode(c.hrp, values, bech32), nil
+}
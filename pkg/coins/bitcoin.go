@@ -0,0 +1,155 @@
+package coins
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/basex"
+	"github.com/pkg/errors"
+)
+
+// script opcodes used in standard output scripts
+const (
+	opDup         = 0x76
+	opHash160     = 0xa9
+	opEqual       = 0x87
+	opEqualVerify = 0x88
+	opCheckSig    = 0xac
+	op1           = 0x51
+	op16          = 0x60
+)
+
+// bitcoinCodec is for Bitcoin and its forks, whose addresses are stored as the
+// scriptPubkey of the output: P2PKH and P2SH for base58check addresses and
+// witness programs for segwit addresses. hrp is empty for coins without segwit.
+type bitcoinCodec struct {
+	p2pkh []byte // base58check version bytes, the first is used for formatting
+	p2sh  []byte
+	hrp   string
+}
+
+func (c *bitcoinCodec) Parse(addr string) ([]byte, error) {
+	if c.hrp != "" && strings.HasPrefix(strings.ToLower(addr), c.hrp+"1") {
+		return c.parseSegwit(addr)
+	}
+
+	b, err := decodeBase58Check(addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 21 {
+		return nil, errors.New("address must be 21 bytes long")
+	}
+
+	version, hash := b[0], b[1:]
+	switch {
+	case bytes.IndexByte(c.p2pkh, version) >= 0:
+		return append(append([]byte{opDup, opHash160, 20}, hash...), opEqualVerify, opCheckSig), nil
+	case bytes.IndexByte(c.p2sh, version) >= 0:
+		return append(append([]byte{opHash160, 20}, hash...), opEqual), nil
+	}
+	return nil, errors.Errorf("unsupported address version 0x%02x", version)
+}
+
+func (c *bitcoinCodec) parseSegwit(addr string) ([]byte, error) {
+	hrp, data, variant, err := bech32Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+	// the separator is the last "1", so the prefix check in Parse is not enough
+	if hrp != c.hrp {
+		return nil, errors.Errorf("address must start with %q", c.hrp+"1")
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return nil, errors.New("invalid witness version")
+	}
+
+	version := data[0]
+	program, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWitnessProgram(version, program); err != nil {
+		return nil, err
+	}
+	if (version == 0) != (variant == bech32) {
+		return nil, errors.New("witness version 0 must use bech32 and later versions bech32m")
+	}
+
+	op := byte(0)
+	if version > 0 {
+		op = op1 + version - 1
+	}
+	return append([]byte{op, byte(len(program))}, program...), nil
+}
+
+func (c *bitcoinCodec) Format(data []byte) (string, error) {
+	switch {
+	case len(data) == 25 && data[0] == opDup && data[1] == opHash160 && data[2] == 20 &&
+		data[23] == opEqualVerify && data[24] == opCheckSig:
+		return encodeBase58Check(append([]byte{c.p2pkh[0]}, data[3:23]...)), nil
+
+	case len(data) == 23 && data[0] == opHash160 && data[1] == 20 && data[22] == opEqual:
+		return encodeBase58Check(append([]byte{c.p2sh[0]}, data[2:22]...)), nil
+
+	case c.hrp != "" && len(data) >= 4 && (data[0] == 0 || data[0] >= op1 && data[0] <= op16) &&
+		int(data[1]) == len(data)-2:
+		version := byte(0)
+		if data[0] != 0 {
+			version = data[0] - op1 + 1
+		}
+		program := data[2:]
+		if err := validateWitnessProgram(version, program); err != nil {
+			return "", err
+		}
+
+		values, err := convertBits(program, 8, 5, true)
+		if err != nil {
+			return "", err
+		}
+		variant := bech32m
+		if version == 0 {
+			variant = bech32
+		}
+		return bech32Encode(c.hrp, append([]byte{version}, values...), variant), nil
+	}
+
+	return "", errors.New("unsupported output script")
+}
+
+func validateWitnessProgram(version byte, program []byte) error {
+	if len(program) < 2 || len(program) > 40 {
+		return errors.New("witness program must be 2 to 40 bytes long")
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return errors.New("version 0 witness program must be 20 or 32 bytes long")
+	}
+	return nil
+}
+
+func decodeBase58Check(s string) ([]byte, error) {
+	b, err := basex.Base58BTC.Decode(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base58 address")
+	}
+	if len(b) < 4 {
+		return nil, errors.New("address is too short")
+	}
+
+	payload, checksum := b[:len(b)-4], b[len(b)-4:]
+	if !bytes.Equal(checksum, doubleSHA256(payload)[:4]) {
+		return nil, errors.New("invalid address checksum")
+	}
+	return payload, nil
+}
+
+func encodeBase58Check(payload []byte) string {
+	return basex.Base58BTC.Encode(append(payload, doubleSHA256(payload)[:4]...))
+}
+
+func doubleSHA256(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:]
+}
@@ -0,0 +1,152 @@
+// Package coins converts cryptocurrency addresses between their human-readable
+// form and the binary form stored in ENS resolvers by addr(bytes32,uint256),
+// keyed by SLIP-44 coin type.
+//
+// https://docs.ens.domains/ensip/9
+// https://docs.ens.domains/ensip/11
+package coins
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SLIP-44 coin types of the built-in codecs
+// https://github.com/satoshilabs/slips/blob/master/slip-0044.md
+const (
+	CoinTypeBTC  = 0
+	CoinTypeLTC  = 2
+	CoinTypeDOGE = 3
+	CoinTypeETH  = 60
+	CoinTypeETC  = 61
+	CoinTypeATOM = 118
+	CoinTypeSOL  = 501
+	CoinTypeBNB  = 714
+)
+
+// ErrUnsupportedCoinType is returned when no codec is registered for a coin type
+var ErrUnsupportedCoinType = errors.New("unsupported coin type")
+
+// Codec converts the addresses of a coin
+type Codec interface {
+	// Parse converts a human-readable address to its binary form, rejecting
+	// malformed addresses and addresses with invalid checksums
+	Parse(addr string) ([]byte, error)
+	// Format converts the binary form of an address to its human-readable form
+	Format(data []byte) (string, error)
+}
+
+// Registry maps coin types to codecs
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[uint64]Codec
+	// evm is used for EVM coin types without a codec of their own
+	evm Codec
+}
+
+// DefaultRegistry is used by Parse and Format and has all the built-in codecs
+// registered
+var DefaultRegistry = NewDefaultRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{codecs: make(map[uint64]Codec)}
+}
+
+// NewDefaultRegistry returns a new registry with all the built-in codecs
+// registered
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.codecs[CoinTypeBTC] = &bitcoinCodec{p2pkh: []byte{0x00}, p2sh: []byte{0x05}, hrp: "bc"}
+	r.codecs[CoinTypeLTC] = &bitcoinCodec{p2pkh: []byte{0x30}, p2sh: []byte{0x32, 0x05}, hrp: "ltc"}
+	r.codecs[CoinTypeDOGE] = &bitcoinCodec{p2pkh: []byte{0x1e}, p2sh: []byte{0x16}}
+	r.codecs[CoinTypeETH] = checksummedHexCodec{}
+	r.codecs[CoinTypeETC] = checksummedHexCodec{}
+	r.codecs[CoinTypeATOM] = &bech32Codec{hrp: "cosmos"}
+	r.codecs[CoinTypeSOL] = solanaCodec{}
+	r.codecs[CoinTypeBNB] = &bech32Codec{hrp: "bnb"}
+	r.evm = checksummedHexCodec{}
+
+	return r
+}
+
+// Register registers a codec for the coin type, replacing any codec previously
+// registered for it
+func (r *Registry) Register(coinType *big.Int, codec Codec) error {
+	if coinType == nil || !coinType.IsUint64() {
+		return errors.New("coin type must be an unsigned 64-bit integer")
+	}
+	if codec == nil {
+		return errors.New("codec must not be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[coinType.Uint64()] = codec
+
+	return nil
+}
+
+// Codec returns the codec registered for the coin type, or
+// ErrUnsupportedCoinType. In the default registry, EVM chain coin types share
+// the Ethereum codec.
+func (r *Registry) Codec(coinType *big.Int) (Codec, error) {
+	if coinType == nil || !coinType.IsUint64() {
+		return nil, ErrUnsupportedCoinType
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if codec, ok := r.codecs[coinType.Uint64()]; ok {
+		return codec, nil
+	}
+	if r.evm != nil && IsEVMCoinType(coinType) {
+		return r.evm, nil
+	}
+	return nil, ErrUnsupportedCoinType
+}
+
+// Parse converts a human-readable address of the coin to its binary form
+func (r *Registry) Parse(coinType *big.Int, addr string) ([]byte, error) {
+	codec, err := r.Codec(coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := codec.Parse(addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address for coin type %s", coinType)
+	}
+	return b, nil
+}
+
+// Format converts the binary form of an address of the coin to its
+// human-readable form
+func (r *Registry) Format(coinType *big.Int, data []byte) (string, error) {
+	codec, err := r.Codec(coinType)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := codec.Format(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid address for coin type %s", coinType)
+	}
+	return s, nil
+}
+
+// Parse converts a human-readable address of the coin to its binary form using
+// the default registry
+func Parse(coinType *big.Int, addr string) ([]byte, error) {
+	return DefaultRegistry.Parse(coinType, addr)
+}
+
+// Format converts the binary form of an address of the coin to its
+// human-readable form using the default registry
+func Format(coinType *big.Int, data []byte) (string, error) {
+	return DefaultRegistry.Format(coinType, data)
+}
@@ -0,0 +1,157 @@
+package coins
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndFormat(t *testing.T) {
+	for _, tc := range []struct {
+		coinType  int64
+		addr      string
+		encoded   string
+		formatted string
+	}{
+		// https://github.com/ensdomains/address-encoder
+		{CoinTypeBTC, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "0x76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac", ""},
+		{CoinTypeBTC, "3Ai1JZ8pdJb2ksieUV8FsxSNVJCpoPi8W6", "0xa91462e907b15cbf27d5425399ebf6f0fb50ebb88f1887", ""},
+		{CoinTypeBTC, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "0x0014751e76e8199196d454941c45d1b3a323f1433bd6", ""},
+		{CoinTypeBTC, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0x0014751e76e8199196d454941c45d1b3a323f1433bd6", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{CoinTypeBTC, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "0x512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", ""},
+		{CoinTypeLTC, "LaMT348PWRnrqeeWArpwQPbuanpXDZGEUz", "0x76a914a5f4d12ce3685781b227c1f39548ddef429e978388ac", ""},
+		{CoinTypeLTC, "MQMcJhpWHYVeQArcZR3sBgyPZxxRtnH441", "0xa914b48297bff5dadecc5f36145cec6a5f20d57c8f9b87", ""},
+		{CoinTypeLTC, "ltc1qdp7p2rpx4a2f80h7a4crvppczgg4egmv5c78w8", "0x0014687c150c26af5493befeed7036043812115ca36c", ""},
+		{CoinTypeDOGE, "DBXu2kgc3xtvCUWFcxFE3r9hEYgmuaaCyD", "0x76a9144620b70031f0e9437e374a2100934fba4911046088ac", ""},
+		{CoinTypeDOGE, "AF8ekvSf6eiSBRspJjnfzK6d1EM6pnPq3G", "0xa914f8f5d99a9fc21aa676e74d15e7b8134557615bda87", ""},
+		{CoinTypeETH, "0x314159265dD8dbb310642f98f50C066173C1259b", "0x314159265dd8dbb310642f98f50c066173c1259b", ""},
+		{CoinTypeETH, "0x314159265dd8dbb310642f98f50c066173c1259b", "0x314159265dd8dbb310642f98f50c066173c1259b", "0x314159265dD8dbb310642f98f50C066173C1259b"},
+		{CoinTypeETC, "0x314159265dD8dbb310642f98f50C066173C1259b", "0x314159265dd8dbb310642f98f50c066173c1259b", ""},
+		{CoinTypeBNB, "bnb1grpf0955h0ykzq3ar5nmum7y6gdfl6lxfn46h2", "0x40c2979694bbc961023d1d27be6fc4d21a9febe6", ""},
+		{CoinTypeSOL, "11111111111111111111111111111111", "0x0000000000000000000000000000000000000000000000000000000000000000", ""},
+	} {
+		coinType := big.NewInt(tc.coinType)
+
+		b, err := Parse(coinType, tc.addr)
+		require.Nil(t, err, tc.addr)
+		require.Equal(t, tc.encoded, hexutil.Encode(b), tc.addr)
+
+		formatted := tc.formatted
+		if formatted == "" {
+			formatted = tc.addr
+		}
+		s, err := Format(coinType, b)
+		require.Nil(t, err, tc.addr)
+		require.Equal(t, formatted, s)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		coinType int64
+		addr     string
+	}{
+		{CoinTypeATOM, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c"},
+		{CoinTypeSOL, "HN7cABqLq46Es1jh92dQQisAq662SmxELLLsHHe4YWrH"},
+		{CoinTypeBTC, "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs"},
+	} {
+		coinType := big.NewInt(tc.coinType)
+
+		b, err := Parse(coinType, tc.addr)
+		require.Nil(t, err, tc.addr)
+
+		s, err := Format(coinType, b)
+		require.Nil(t, err, tc.addr)
+		require.Equal(t, tc.addr, s)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		coinType int64
+		addr     string
+		err      string
+	}{
+		// checksum typo
+		{CoinTypeBTC, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "invalid address checksum"},
+		// litecoin address for bitcoin
+		{CoinTypeBTC, "LaMT348PWRnrqeeWArpwQPbuanpXDZGEUz", "unsupported address version 0x30"},
+		{CoinTypeBTC, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "invalid bech32 checksum"},
+		{CoinTypeBTC, "bc1Qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "must not be mixed case"},
+		// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki#test-vectors-for-v0-v16-native-segregated-witness-addresses
+		{CoinTypeBTC, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", "must use bech32 and later versions bech32m"},
+		{CoinTypeBTC, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", "must use bech32 and later versions bech32m"},
+		{CoinTypeBTC, "bc1rw5uspcuh", "witness program must be 2 to 40 bytes long"},
+		{CoinTypeBTC, "ltc1qdp7p2rpx4a2f80h7a4crvppczgg4egmv5c78w8", "invalid base58 address"},
+		// valid bech32 with a different human-readable part
+		{CoinTypeBTC, "bc1evil1qqurswpc8qurswpc8qurswpc8qurswpc8najymw", `address must start with "bc1"`},
+		{CoinTypeLTC, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "invalid base58 address"},
+		{CoinTypeLTC, "ltc1evil1qqurswpc8qurswpc8qurswpc8qurswpc8v5esqd", `address must start with "ltc1"`},
+		{CoinTypeDOGE, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "unsupported address version 0x00"},
+		{CoinTypeETH, "0x314159265dD8dbb310642f98f50C066173C1259B", "invalid address checksum"},
+		{CoinTypeETH, "314159265dd8dbb310642f98f50c066173c1259b", "address must be 0x followed by 40 hex characters"},
+		{CoinTypeETH, "0x314159265dd8dbb310642f98f50c066173c125", "address must be 0x followed by 40 hex characters"},
+		{CoinTypeSOL, "1111111111111111111111111111111", "address must be 32 bytes long"},
+		{CoinTypeSOL, "0OIl", "invalid base58 address"},
+		{CoinTypeATOM, "bnb1grpf0955h0ykzq3ar5nmum7y6gdfl6lxfn46h2", `address must start with "cosmos1"`},
+	} {
+		b, err := Parse(big.NewInt(tc.coinType), tc.addr)
+		require.Nil(t, b, tc.addr)
+		require.NotNil(t, err, tc.addr)
+		require.Contains(t, err.Error(), tc.err, tc.addr)
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		coinType int64
+		data     string
+		err      string
+	}{
+		{CoinTypeBTC, "0x76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888", "unsupported output script"},
+		{CoinTypeBTC, "0x0013751e76e8199196d454941c45d1b3a323f1433b", "version 0 witness program must be 20 or 32 bytes long"},
+		{CoinTypeDOGE, "0x0014751e76e8199196d454941c45d1b3a323f1433bd6", "unsupported output script"},
+		{CoinTypeETH, "0x1234", "address must be 20 bytes long"},
+		{CoinTypeSOL, "0x1234", "address must be 32 bytes long"},
+		{CoinTypeATOM, "0x", "address is empty"},
+	} {
+		s, err := Format(big.NewInt(tc.coinType), hexutil.MustDecode(tc.data))
+		require.Empty(t, s, tc.data)
+		require.NotNil(t, err, tc.data)
+		require.Contains(t, err.Error(), tc.err, tc.data)
+	}
+}
+
+func TestUnsupportedCoinType(t *testing.T) {
+	_, err := Parse(big.NewInt(999999), "addr")
+	require.Equal(t, ErrUnsupportedCoinType, err)
+
+	_, err = Format(new(big.Int).Lsh(big.NewInt(1), 64), []byte{0x01})
+	require.Equal(t, ErrUnsupportedCoinType, err)
+}
+
+type upperCodec struct{}
+
+func (upperCodec) Parse(addr string) ([]byte, error)  { return []byte(addr), nil }
+func (upperCodec) Format(data []byte) (string, error) { return string(data), nil }
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+
+	_, err := r.Codec(big.NewInt(CoinTypeETH))
+	require.Equal(t, ErrUnsupportedCoinType, err)
+
+	require.Nil(t, r.Register(big.NewInt(CoinTypeETH), upperCodec{}))
+	b, err := r.Parse(big.NewInt(CoinTypeETH), "hello")
+	require.Nil(t, err)
+	require.Equal(t, []byte("hello"), b)
+
+	require.EqualError(t, r.Register(big.NewInt(-1), upperCodec{}), "coin type must be an unsigned 64-bit integer")
+	require.EqualError(t, r.Register(big.NewInt(1), nil), "codec must not be nil")
+
+	// the default registry is unaffected
+	_, err = Parse(big.NewInt(CoinTypeETH), "hello")
+	require.NotNil(t, err)
+}
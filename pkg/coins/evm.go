@@ -0,0 +1,60 @@
+package coins
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// CoinTypeDefaultEVM is the coin type of the default address used on EVM
+// chains that have no address of their own
+// https://docs.ens.domains/ensip/19
+const CoinTypeDefaultEVM = 0x80000000
+
+// evmBit is set in the coin types of EVM chains, which are evmBit | chainId
+// https://docs.ens.domains/ensip/11
+const evmBit = 0x80000000
+
+// IsSLIP44CoinType reports whether the coin type is a SLIP-44 coin type rather
+// than an ENSIP-11 EVM chain coin type. Note that 60 is both the SLIP-44 coin
+// type of ETH and the coin type of chain 1.
+func IsSLIP44CoinType(coinType *big.Int) bool {
+	return coinType.Sign() >= 0 && coinType.Cmp(big.NewInt(evmBit)) < 0
+}
+
+// IsEVMCoinType reports whether addresses of the coin type are EVM addresses:
+// 60, the coin type of an EVM chain or CoinTypeDefaultEVM
+func IsEVMCoinType(coinType *big.Int) bool {
+	if coinType.IsUint64() && coinType.Uint64() == CoinTypeETH {
+		return true
+	}
+	return coinType.IsUint64() && coinType.Uint64()&^0x7fffffff == evmBit
+}
+
+// IsDefaultEVMCoinType reports whether the coin type is CoinTypeDefaultEVM
+func IsDefaultEVMCoinType(coinType *big.Int) bool {
+	return coinType.IsUint64() && coinType.Uint64() == CoinTypeDefaultEVM
+}
+
+// EVMChainID returns the chain ID of an EVM coin type, 1 for 60. ok is false
+// for CoinTypeDefaultEVM and for coin types that are not EVM coin types.
+func EVMChainID(coinType *big.Int) (chainID uint64, ok bool) {
+	if !IsEVMCoinType(coinType) || IsDefaultEVMCoinType(coinType) {
+		return 0, false
+	}
+	if coinType.Uint64() == CoinTypeETH {
+		return 1, true
+	}
+	return coinType.Uint64() ^ evmBit, true
+}
+
+// EVMCoinType returns the coin type of the EVM chain, 60 for chain 1
+func EVMCoinType(chainID uint64) (*big.Int, error) {
+	if chainID == 0 || chainID >= evmBit {
+		return nil, errors.New("chain ID must be between 1 and 0x7fffffff")
+	}
+	if chainID == 1 {
+		return big.NewInt(CoinTypeETH), nil
+	}
+	return new(big.Int).SetUint64(evmBit | chainID), nil
+}
@@ -0,0 +1,71 @@
+package coins
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEVMCoinTypes(t *testing.T) {
+	for _, tc := range []struct {
+		coinType  *big.Int
+		slip44    bool
+		evm       bool
+		isDefault bool
+		chainID   uint64
+	}{
+		{big.NewInt(CoinTypeBTC), true, false, false, 0},
+		{big.NewInt(CoinTypeETH), true, true, false, 1},
+		{big.NewInt(0x7fffffff), true, false, false, 0},
+		{big.NewInt(CoinTypeDefaultEVM), false, true, true, 0},
+		{big.NewInt(0x8000000a), false, true, false, 10},   // Optimism
+		{big.NewInt(0x80002105), false, true, false, 8453}, // Base
+		{big.NewInt(0xffffffff), false, true, false, 0x7fffffff},
+		{big.NewInt(0x100000000), false, false, false, 0},
+		{big.NewInt(0x18000000a), false, false, false, 0},
+		{new(big.Int).Lsh(big.NewInt(1), 255), false, false, false, 0},
+	} {
+		require.Equal(t, tc.slip44, IsSLIP44CoinType(tc.coinType), tc.coinType.String())
+		require.Equal(t, tc.evm, IsEVMCoinType(tc.coinType), tc.coinType.String())
+		require.Equal(t, tc.isDefault, IsDefaultEVMCoinType(tc.coinType), tc.coinType.String())
+
+		chainID, ok := EVMChainID(tc.coinType)
+		require.Equal(t, tc.chainID != 0, ok, tc.coinType.String())
+		require.Equal(t, tc.chainID, chainID, tc.coinType.String())
+
+		if ok {
+			coinType, err := EVMCoinType(chainID)
+			require.Nil(t, err)
+			require.Equal(t, tc.coinType, coinType)
+		}
+	}
+}
+
+func TestEVMCoinTypeInvalid(t *testing.T) {
+	for _, chainID := range []uint64{0, 0x80000000, 0xffffffffffffffff} {
+		coinType, err := EVMCoinType(chainID)
+		require.Nil(t, coinType)
+		require.EqualError(t, err, "chain ID must be between 1 and 0x7fffffff")
+	}
+}
+
+func TestEVMCodec(t *testing.T) {
+	for _, coinType := range []int64{CoinTypeDefaultEVM, 0x8000000a, 0xffffffff} {
+		b, err := Parse(big.NewInt(coinType), "0x314159265dD8dbb310642f98f50C066173C1259b")
+		require.Nil(t, err)
+		require.Equal(t, "0x314159265dd8dbb310642f98f50c066173c1259b", hexutil.Encode(b))
+
+		s, err := Format(big.NewInt(coinType), b)
+		require.Nil(t, err)
+		require.Equal(t, "0x314159265dD8dbb310642f98f50C066173C1259b", s)
+	}
+
+	_, err := Parse(big.NewInt(0x100000000), "0x314159265dD8dbb310642f98f50C066173C1259b")
+	require.Equal(t, ErrUnsupportedCoinType, err)
+
+	// only the default registry falls back to the Ethereum codec
+	_, err = NewRegistry().Codec(big.NewInt(0x8000000a))
+	require.Equal(t, ErrUnsupportedCoinType, err)
+}
@@ -0,0 +1,34 @@
+package coins
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// checksummedHexCodec is for Ethereum and coins sharing its address format,
+// stored as the 20 address bytes. Mixed-case addresses must have a valid
+// EIP-55 checksum.
+// https://eips.ethereum.org/EIPS/eip-55
+type checksummedHexCodec struct{}
+
+func (checksummedHexCodec) Parse(addr string) ([]byte, error) {
+	if !strings.HasPrefix(addr, "0x") || len(addr) != 42 || !common.IsHexAddress(addr) {
+		return nil, errors.New("address must be 0x followed by 40 hex characters")
+	}
+
+	a := common.HexToAddress(addr)
+	digits := addr[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && addr != a.Hex() {
+		return nil, errors.New("invalid address checksum")
+	}
+	return a.Bytes(), nil
+}
+
+func (checksummedHexCodec) Format(data []byte) (string, error) {
+	if len(data) != common.AddressLength {
+		return "", errors.New("address must be 20 bytes long")
+	}
+	return common.BytesToAddress(data).Hex(), nil
+}
@@ -0,0 +1,28 @@
+package coins
+
+import (
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/basex"
+	"github.com/pkg/errors"
+)
+
+// solanaCodec is for Solana, whose addresses are base58-encoded 32-byte
+// public keys without a checksum
+type solanaCodec struct{}
+
+func (solanaCodec) Parse(addr string) ([]byte, error) {
+	b, err := basex.Base58BTC.Decode(addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base58 address")
+	}
+	if len(b) != 32 {
+		return nil, errors.New("address must be 32 bytes long")
+	}
+	return b, nil
+}
+
+func (solanaCodec) Format(data []byte) (string, error) {
+	if len(data) != 32 {
+		return "", errors.New("address must be 32 bytes long")
+	}
+	return basex.Base58BTC.Encode(data), nil
+}
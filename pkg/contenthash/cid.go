@@ -0,0 +1,139 @@
+package contenthash
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/basex"
+	"github.com/pkg/errors"
+)
+
+// multicodec content types used inside CIDs
+const (
+	cidCodecDagPb         = 0x70
+	cidCodecLibp2pKey     = 0x72
+	cidCodecSwarmManifest = 0xfa
+)
+
+// multihash function codes
+const (
+	multihashIdentity  = 0x00
+	multihashSha256    = 0x12
+	multihashKeccak256 = 0x1b
+)
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// parseCID parses a CIDv0 (base58btc "Qm...") or a multibase-prefixed CIDv1
+// string and returns the binary CIDv1. A CIDv0 is upgraded to a CIDv1 with the
+// given default codec.
+func parseCID(s string, v0Codec uint64) ([]byte, error) {
+	if len(s) == 0 {
+		return nil, errors.New("empty CID")
+	}
+
+	// CIDv0 and bare peer IDs are base58btc-encoded multihashes without a multibase prefix
+	if strings.HasPrefix(s, "Qm") || strings.HasPrefix(s, "1") {
+		mh, err := basex.Base58BTC.Decode(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base58 CID")
+		}
+		if err := validateMultihash(mh); err != nil {
+			return nil, err
+		}
+		return append(appendUvarint([]byte{0x01}, v0Codec), mh...), nil
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+	switch s[0] {
+	case 'b':
+		b, err = base32Lower.DecodeString(s[1:])
+	case 'B':
+		b, err = base32Lower.DecodeString(strings.ToLower(s[1:]))
+	case 'k':
+		b, err = basex.Base36.Decode(s[1:])
+	case 'K':
+		b, err = basex.Base36.Decode(strings.ToLower(s[1:]))
+	case 'z':
+		b, err = basex.Base58BTC.Decode(s[1:])
+	case 'f', 'F':
+		b, err = hex.DecodeString(s[1:])
+	default:
+		return nil, errors.Errorf("unsupported multibase prefix %q", s[0])
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid multibase encoding")
+	}
+
+	if err := validateCID(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// validateCID checks that b is a well-formed binary CIDv1
+func validateCID(b []byte) error {
+	version, n := binary.Uvarint(b)
+	if n <= 0 {
+		return errors.New("invalid CID version")
+	}
+	if version != 1 {
+		return errors.Errorf("unsupported CID version %d", version)
+	}
+	b = b[n:]
+
+	if _, n = binary.Uvarint(b); n <= 0 {
+		return errors.New("invalid CID codec")
+	}
+
+	return validateMultihash(b[n:])
+}
+
+func validateMultihash(mh []byte) error {
+	_, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return errors.New("invalid multihash function code")
+	}
+	mh = mh[n:]
+
+	length, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return errors.New("invalid multihash length")
+	}
+	if uint64(len(mh[n:])) != length {
+		return errors.New("multihash digest length mismatch")
+	}
+	return nil
+}
+
+// cidCodec returns the content codec of a binary CIDv1 that has already been validated
+func cidCodec(b []byte) uint64 {
+	_, n := binary.Uvarint(b)
+	codec, _ := binary.Uvarint(b[n:])
+	return codec
+}
+
+// cidMultihash returns the multihash of a binary CIDv1 that has already been validated
+func cidMultihash(b []byte) []byte {
+	_, n := binary.Uvarint(b)
+	_, m := binary.Uvarint(b[n:])
+	return b[n+m:]
+}
+
+func formatCIDBase32(b []byte) string {
+	return "b" + base32Lower.EncodeToString(b)
+}
+
+func formatCIDBase36(b []byte) string {
+	return "k" + basex.Base36.Encode(b)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(b, buf[:binary.PutUvarint(buf, v)]...)
+}
@@ -0,0 +1,254 @@
+// Package contenthash encodes and decodes EIP-1577 contenthash values.
+//
+// https://eips.ethereum.org/EIPS/eip-1577
+package contenthash
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Codec is the multicodec code prefixed to the contenthash value
+type Codec uint64
+
+const (
+	CodecIPFS    Codec = 0xe3     // ipfs-ns
+	CodecSwarm   Codec = 0xe4     // swarm-ns
+	CodecIPNS    Codec = 0xe5     // ipns-ns
+	CodecOnion   Codec = 0x01bc   // onion
+	CodecOnion3  Codec = 0x01bd   // onion3
+	CodecSkynet  Codec = 0xb19910 // skynet-ns
+	CodecArweave Codec = 0xb29910 // arweave-ns
+)
+
+var schemes = map[Codec]string{
+	CodecIPFS:    "ipfs",
+	CodecSwarm:   "bzz",
+	CodecIPNS:    "ipns",
+	CodecOnion:   "onion",
+	CodecOnion3:  "onion3",
+	CodecSkynet:  "sia",
+	CodecArweave: "ar",
+}
+
+func (c Codec) String() string {
+	switch c {
+	case CodecIPFS:
+		return "ipfs-ns"
+	case CodecSwarm:
+		return "swarm-ns"
+	case CodecIPNS:
+		return "ipns-ns"
+	case CodecOnion:
+		return "onion"
+	case CodecOnion3:
+		return "onion3"
+	case CodecSkynet:
+		return "skynet-ns"
+	case CodecArweave:
+		return "arweave-ns"
+	}
+	return fmt.Sprintf("0x%x", uint64(c))
+}
+
+// Scheme returns the URI scheme used for the codec in the human-readable form,
+// e.g. "ipfs" for ipfs://
+func (c Codec) Scheme() string {
+	return schemes[c]
+}
+
+// ContentHash is a decoded contenthash record. Content holds the codec-specific
+// payload: a binary CIDv1 for ipfs, ipns and swarm, the raw transaction ID for
+// arweave, the raw skylink for skynet and the ASCII address for onion/onion3.
+type ContentHash struct {
+	Codec   Codec
+	Content []byte
+}
+
+// Parse parses a human-readable contenthash such as "ipfs://bafy...",
+// "ipns://k51...", "bzz://<hex>", "onion3://<address>", "ar://<txid>" or
+// "sia://<skylink>"
+func Parse(uri string) (*ContentHash, error) {
+	i := strings.Index(uri, "://")
+	if i < 0 {
+		return nil, errors.New("contenthash must be in the form <scheme>://<value>")
+	}
+	scheme, value := strings.ToLower(uri[:i]), strings.TrimSuffix(uri[i+3:], "/")
+
+	switch scheme {
+	case "ipfs":
+		cid, err := parseCID(value, cidCodecDagPb)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ipfs CID")
+		}
+		return &ContentHash{CodecIPFS, cid}, nil
+
+	case "ipns":
+		cid, err := parseCID(value, cidCodecLibp2pKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ipns name")
+		}
+		return &ContentHash{CodecIPNS, cid}, nil
+
+	case "bzz":
+		h, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil || len(h) != 32 {
+			return nil, errors.New("swarm hash must be 32 bytes in hex")
+		}
+		cid := []byte{0x01}
+		cid = appendUvarint(cid, cidCodecSwarmManifest)
+		cid = append(cid, multihashKeccak256, 32)
+		return &ContentHash{CodecSwarm, append(cid, h...)}, nil
+
+	case "onion":
+		if err := validateOnion(value, 16); err != nil {
+			return nil, err
+		}
+		return &ContentHash{CodecOnion, []byte(value)}, nil
+
+	case "onion3":
+		if err := validateOnion(value, 56); err != nil {
+			return nil, err
+		}
+		return &ContentHash{CodecOnion3, []byte(value)}, nil
+
+	case "ar":
+		b, err := decodeBase64URL(value, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid arweave transaction id")
+		}
+		return &ContentHash{CodecArweave, b}, nil
+
+	case "sia":
+		b, err := decodeBase64URL(value, 34)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid skylink")
+		}
+		return &ContentHash{CodecSkynet, b}, nil
+	}
+
+	return nil, errors.Errorf("unsupported contenthash scheme: %s", scheme)
+}
+
+// Decode decodes the raw bytes of a contenthash record as returned by
+// contenthash(bytes32)
+func Decode(data []byte) (*ContentHash, error) {
+	if len(data) == 0 {
+		return nil, errors.New("contenthash is empty")
+	}
+
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errors.New("invalid contenthash codec")
+	}
+	ch := &ContentHash{Codec(code), append([]byte{}, data[n:]...)}
+
+	if err := ch.Validate(); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Validate checks that the content is well-formed for the codec
+func (ch *ContentHash) Validate() error {
+	switch ch.Codec {
+	case CodecIPFS, CodecIPNS:
+		if err := validateCID(ch.Content); err != nil {
+			return errors.Wrapf(err, "invalid %s CID", ch.Codec.Scheme())
+		}
+
+	case CodecSwarm:
+		if err := validateCID(ch.Content); err != nil {
+			return errors.Wrap(err, "invalid swarm CID")
+		}
+		mh := cidMultihash(ch.Content)
+		if cidCodec(ch.Content) != cidCodecSwarmManifest || len(mh) != 34 || mh[0] != multihashKeccak256 {
+			return errors.New("swarm CID must be a keccak-256 swarm-manifest")
+		}
+
+	case CodecOnion:
+		return validateOnion(string(ch.Content), 16)
+
+	case CodecOnion3:
+		return validateOnion(string(ch.Content), 56)
+
+	case CodecArweave:
+		if len(ch.Content) != 32 {
+			return errors.New("arweave transaction id must be 32 bytes long")
+		}
+
+	case CodecSkynet:
+		if len(ch.Content) != 34 {
+			return errors.New("skylink must be 34 bytes long")
+		}
+
+	default:
+		return errors.Errorf("unsupported contenthash codec: %s", ch.Codec)
+	}
+
+	return nil
+}
+
+// Encode returns the raw bytes of the contenthash record, suitable for
+// ContenthashLookup.EncodeResult
+func (ch *ContentHash) Encode() []byte {
+	return append(appendUvarint(nil, uint64(ch.Codec)), ch.Content...)
+}
+
+// String returns the human-readable form of the contenthash. CIDs are formatted
+// as base32 CIDv1 for ipfs and base36 CIDv1 for ipns. Contenthash values that
+// fail Validate are formatted as the codec followed by the hex-encoded content.
+func (ch *ContentHash) String() string {
+	if err := ch.Validate(); err != nil {
+		return fmt.Sprintf("%s:0x%s", ch.Codec, hex.EncodeToString(ch.Content))
+	}
+
+	var value string
+	switch ch.Codec {
+	case CodecIPFS:
+		value = formatCIDBase32(ch.Content)
+	case CodecIPNS:
+		value = formatCIDBase36(ch.Content)
+	case CodecSwarm:
+		value = hex.EncodeToString(ch.Content[len(ch.Content)-32:])
+	case CodecOnion, CodecOnion3:
+		value = string(ch.Content)
+	case CodecArweave, CodecSkynet:
+		value = base64.RawURLEncoding.EncodeToString(ch.Content)
+	}
+	return ch.Codec.Scheme() + "://" + value
+}
+
+// Equal reports whether two contenthash values are identical
+func (ch *ContentHash) Equal(other *ContentHash) bool {
+	return ch.Codec == other.Codec && bytes.Equal(ch.Content, other.Content)
+}
+
+func validateOnion(addr string, length int) error {
+	if len(addr) != length {
+		return errors.Errorf("onion address must be %d characters long", length)
+	}
+	for _, c := range addr {
+		if !(c >= 'a' && c <= 'z') && !(c >= '2' && c <= '7') {
+			return errors.New("onion address must be lowercase base32")
+		}
+	}
+	return nil
+}
+
+func decodeBase64URL(s string, length int) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != length {
+		return nil, errors.Errorf("expected %d bytes, got %d", length, len(b))
+	}
+	return b, nil
+}
@@ -0,0 +1,116 @@
+package contenthash
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndEncode(t *testing.T) {
+	for _, tc := range []struct {
+		uri       string
+		codec     Codec
+		encoded   string
+		formatted string
+	}{
+		{
+			// https://eips.ethereum.org/EIPS/eip-1577#example
+			uri:       "ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4",
+			codec:     CodecIPFS,
+			encoded:   "0xe3010170122029f2d17be6139079dc48696d1f582a8530eb9805b561eda517e22a892c7e3f1f",
+			formatted: "ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4",
+		},
+		{
+			uri:       "ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4",
+			codec:     CodecIPFS,
+			encoded:   "0xe3010170122029f2d17be6139079dc48696d1f582a8530eb9805b561eda517e22a892c7e3f1f",
+			formatted: "ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7d4",
+		},
+		{
+			uri:       "bzz://d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+			codec:     CodecSwarm,
+			encoded:   "0xe40101fa011b20d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+			formatted: "bzz://d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+		},
+		{
+			uri:       "onion://zqktlwi4fecvo6ri",
+			codec:     CodecOnion,
+			encoded:   "0xbc037a716b746c776934666563766f367269",
+			formatted: "onion://zqktlwi4fecvo6ri",
+		},
+		{
+			uri:       "ar://ys32Pt8uC7TrVxHdOLByOspfPEq2LO63wREHQIM9SJQ",
+			codec:     CodecArweave,
+			encoded:   "0x90b2ca05cacdf63edf2e0bb4eb5711dd38b0723aca5f3c4ab62ceeb7c1110740833d4894",
+			formatted: "ar://ys32Pt8uC7TrVxHdOLByOspfPEq2LO63wREHQIM9SJQ",
+		},
+	} {
+		ch, err := Parse(tc.uri)
+		require.Nil(t, err, tc.uri)
+		require.Equal(t, tc.codec, ch.Codec)
+		require.Equal(t, tc.encoded, hexutil.Encode(ch.Encode()))
+		require.Equal(t, tc.formatted, ch.String())
+
+		decoded, err := Decode(ch.Encode())
+		require.Nil(t, err, tc.uri)
+		require.True(t, ch.Equal(decoded))
+	}
+}
+
+func TestParseIPNSPeerID(t *testing.T) {
+	ch, err := Parse("ipns://12D3KooWJGwvm1MpRqHVT4QdZxM4THqYJrCX2nyyoLfXbf4iPyHk")
+	require.Nil(t, err)
+	require.Equal(t, CodecIPNS, ch.Codec)
+
+	reparsed, err := Parse(ch.String())
+	require.Nil(t, err)
+	require.True(t, ch.Equal(reparsed))
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, uri := range []string{
+		"QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4",
+		"ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD",
+		"ipfs://bafybeibj6lixxzqtsb45ysdjnupvqkufgdvzqbnvmhw2kf7cfkesy7r7",
+		"ipfs://xyz",
+		"bzz://d1de9994",
+		"onion://zqktlwi4fecvo6r1",
+		"onion3://zqktlwi4fecvo6ri",
+		"ar://ys32Pt8uC7TrVxHdOLByOspfPEq2LO63wREHQIM9S",
+		"ftp://example.com",
+	} {
+		ch, err := Parse(uri)
+		require.Nil(t, ch, uri)
+		require.NotNil(t, err, uri)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		"0x",
+		"0xe301017012",
+		"0xe40101701b20d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+		"0xbc037a71",
+		"0x0155",
+	} {
+		ch, err := Decode(hexutil.MustDecode(data))
+		require.Nil(t, ch, data)
+		require.NotNil(t, err, data)
+	}
+}
+
+func TestStringInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		ch        *ContentHash
+		formatted string
+	}{
+		{&ContentHash{CodecSwarm, []byte{0x01, 0x02}}, "swarm-ns:0x0102"},
+		{&ContentHash{CodecSwarm, nil}, "swarm-ns:0x"},
+		{&ContentHash{CodecIPFS, []byte{0x01}}, "ipfs-ns:0x01"},
+		{&ContentHash{CodecArweave, []byte{0xab}}, "arweave-ns:0xab"},
+		{&ContentHash{Codec(0x55), []byte{0xab}}, "0x55:0xab"},
+	} {
+		require.Equal(t, tc.formatted, tc.ch.String())
+	}
+}
@@ -0,0 +1,825 @@
+package namehash
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ENSIP-15 name normalization
+// https://docs.ens.domains/ensip/15
+
+//go:generate go run gen.go -spec spec.json -nf nf.json -out data/ensip15.json.gz
+
+//go:embed data/ensip15.json.gz
+var ensip15Data []byte
+
+var (
+	ErrEmptyLabel            = errors.New("empty label")
+	ErrDisallowedCharacter   = errors.New("disallowed character")
+	ErrInvalidLabelExtension = errors.New("invalid label extension")
+	ErrLeadingUnderscore     = errors.New("underscore allowed only at start")
+	ErrLeadingCombiningMark  = errors.New("leading combining mark")
+	ErrEmojiCombiningMark    = errors.New("emoji + combining mark")
+	ErrFencedLeading         = errors.New("leading fenced")
+	ErrFencedAdjacent        = errors.New("adjacent fenced")
+	ErrFencedTrailing        = errors.New("trailing fenced")
+	ErrIllegalMixture        = errors.New("illegal mixture")
+	ErrWholeScriptConfusable = errors.New("whole-script confusable")
+	ErrDuplicateNSM          = errors.New("duplicate non-spacing marks")
+	ErrExcessiveNSM          = errors.New("excessive non-spacing marks")
+)
+
+const (
+	fe0f       = 0xFE0F
+	labelStop  = '.'
+	hyphen     = '-'
+	underscore = '_'
+	// ξ, which is beautified to Ξ outside of Greek labels
+	greekSmallXi   = 0x3BE
+	greekCapitalXi = 0x39E
+)
+
+// runeRange is an inclusive range of codepoints
+type runeRange struct {
+	lo, hi rune
+}
+
+// runeSet is a sorted list of non-overlapping ranges
+type runeSet []runeRange
+
+func (s runeSet) contains(cp rune) bool {
+	i := sort.Search(len(s), func(i int) bool { return s[i].hi >= cp })
+	return i < len(s) && s[i].lo <= cp
+}
+
+func (s runeSet) each(f func(cp rune)) {
+	for _, r := range s {
+		for cp := r.lo; cp <= r.hi; cp++ {
+			f(cp)
+		}
+	}
+}
+
+// newRuneSet decodes a set encoded as [gap, length, gap, length, ...]
+func newRuneSet(encoded []int) runeSet {
+	s := make(runeSet, 0, len(encoded)/2)
+	next := 0
+	for i := 0; i+1 < len(encoded); i += 2 {
+		lo := next + encoded[i]
+		next = lo + encoded[i+1]
+		s = append(s, runeRange{rune(lo), rune(next - 1)})
+	}
+	return s
+}
+
+// runeSetFromMap returns the set of the codepoints in m
+func runeSetFromMap(m map[rune]bool) runeSet {
+	cps := make([]int, 0, len(m))
+	for cp := range m {
+		cps = append(cps, int(cp))
+	}
+	sort.Ints(cps)
+
+	var s runeSet
+	for _, cp := range cps {
+		if n := len(s); n > 0 && s[n-1].hi+1 == rune(cp) {
+			s[n-1].hi = rune(cp)
+			continue
+		}
+		s = append(s, runeRange{rune(cp), rune(cp)})
+	}
+	return s
+}
+
+// coverage returns the codepoints in at least one of the sets, and those in
+// exactly one of them
+func coverage(sets []runeSet) (union runeSet, single runeSet) {
+	// sweep over the boundaries of the ranges, counting the open ones
+	deltas := make(map[rune]int)
+	for _, s := range sets {
+		for _, r := range s {
+			deltas[r.lo]++
+			deltas[r.hi+1]--
+		}
+	}
+	bounds := make([]rune, 0, len(deltas))
+	for cp := range deltas {
+		bounds = append(bounds, cp)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	appendRange := func(s runeSet, lo, hi rune) runeSet {
+		if n := len(s); n > 0 && s[n-1].hi+1 == lo {
+			s[n-1].hi = hi
+			return s
+		}
+		return append(s, runeRange{lo, hi})
+	}
+
+	open := 0
+	for i, cp := range bounds {
+		open += deltas[cp]
+		if open == 0 || i+1 == len(bounds) {
+			continue
+		}
+		hi := bounds[i+1] - 1
+		union = appendRange(union, cp, hi)
+		if open == 1 {
+			single = appendRange(single, cp, hi)
+		}
+	}
+	return union, single
+}
+
+// without returns the set minus the keys of m
+func (s runeSet) without(m map[rune][]int) runeSet {
+	var out runeSet
+	for _, r := range s {
+		lo := r.lo
+		for cp := r.lo; cp <= r.hi; cp++ {
+			if _, ok := m[cp]; ok {
+				if lo < cp {
+					out = append(out, runeRange{lo, cp - 1})
+				}
+				lo = cp + 1
+			}
+		}
+		if lo <= r.hi {
+			out = append(out, runeRange{lo, r.hi})
+		}
+	}
+	return out
+}
+
+type scriptGroup struct {
+	index      int
+	name       string
+	restricted bool
+	// cm is set if the group allows any sequence of combining marks
+	cm        bool
+	primary   runeSet
+	secondary runeSet
+}
+
+func (g *scriptGroup) contains(cp rune) bool {
+	return g.primary.contains(cp) || g.secondary.contains(cp)
+}
+
+func (g *scriptGroup) String() string {
+	if g.restricted {
+		return fmt.Sprintf("Restricted[%s]", g.name)
+	}
+	return g.name
+}
+
+type emojiSequence struct {
+	normalized []rune
+	beautified []rune
+}
+
+type emojiNode struct {
+	emoji    *emojiSequence
+	children map[rune]*emojiNode
+}
+
+func (n *emojiNode) child(cp rune) *emojiNode {
+	if n.children == nil {
+		n.children = make(map[rune]*emojiNode)
+	}
+	c, ok := n.children[cp]
+	if !ok {
+		c = &emojiNode{}
+		n.children[cp] = c
+	}
+	return c
+}
+
+type ensip15 struct {
+	unicode      string
+	nf           *normalizer
+	ignored      runeSet
+	escape       runeSet
+	cm           runeSet
+	nsm          runeSet
+	nsmMax       int
+	fenced       map[rune]string
+	mapped       map[rune][]rune
+	groups       []*scriptGroup
+	greek        *scriptGroup
+	emojiRoot    *emojiNode
+	valid        runeSet
+	confusables  map[rune][]int
+	uniqueValids runeSet
+}
+
+var (
+	specOnce sync.Once
+	spec     *ensip15
+)
+
+func loadSpec() *ensip15 {
+	specOnce.Do(func() {
+		var err error
+		if spec, err = parseSpec(ensip15Data); err != nil {
+			panic(err)
+		}
+	})
+	return spec
+}
+
+type specJSON struct {
+	Unicode string          `json:"unicode"`
+	Emoji   [][]rune        `json:"emoji"`
+	Ignored []int           `json:"ignored"`
+	Mapped  []mappingJSON   `json:"mapped"`
+	Fenced  [][]interface{} `json:"fenced"`
+	Wholes  []struct {
+		Valid    []int `json:"valid"`
+		Confused []int `json:"confused"`
+	} `json:"wholes"`
+	CM     []int `json:"cm"`
+	NSM    []int `json:"nsm"`
+	NSMMax int   `json:"nsm_max"`
+	Escape []int `json:"escape"`
+	Groups []struct {
+		Name       string `json:"name"`
+		Restricted bool   `json:"restricted"`
+		CM         bool   `json:"cm"`
+		Primary    []int  `json:"primary"`
+		Secondary  []int  `json:"secondary"`
+	} `json:"groups"`
+	NF struct {
+		Ranks      [][]int       `json:"ranks"`
+		Exclusions []int         `json:"exclusions"`
+		Decomp     []mappingJSON `json:"decomp"`
+	} `json:"nf"`
+}
+
+// mappingJSON is a [cp, [cp, ...]] pair
+type mappingJSON struct {
+	from rune
+	to   []rune
+}
+
+func (m *mappingJSON) UnmarshalJSON(data []byte) error {
+	var pair [2]json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(pair[0], &m.from); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &m.to)
+}
+
+func parseSpec(data []byte) (*ensip15, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var s specJSON
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, err
+	}
+
+	l := &ensip15{
+		unicode: s.Unicode,
+		nf: &normalizer{
+			decomps: make(map[rune][]rune, len(s.NF.Decomp)),
+			recomps: make(map[[2]rune]rune),
+			ranks:   make(map[rune]int),
+		},
+		ignored:     newRuneSet(s.Ignored),
+		escape:      newRuneSet(s.Escape),
+		cm:          newRuneSet(s.CM),
+		nsm:         newRuneSet(s.NSM),
+		nsmMax:      s.NSMMax,
+		fenced:      make(map[rune]string, len(s.Fenced)),
+		mapped:      make(map[rune][]rune, len(s.Mapped)),
+		emojiRoot:   &emojiNode{},
+		confusables: make(map[rune][]int),
+	}
+
+	exclusions := newRuneSet(s.NF.Exclusions)
+	for _, d := range s.NF.Decomp {
+		l.nf.decomps[d.from] = d.to
+		if len(d.to) == 2 && !exclusions.contains(d.from) {
+			l.nf.recomps[[2]rune{d.to[0], d.to[1]}] = d.from
+		}
+	}
+	for i, ranked := range s.NF.Ranks {
+		newRuneSet(ranked).each(func(cp rune) {
+			l.nf.ranks[cp] = i + 1
+		})
+	}
+
+	for _, m := range s.Mapped {
+		l.mapped[m.from] = m.to
+	}
+	for _, f := range s.Fenced {
+		cp, ok1 := f[0].(float64)
+		name, ok2 := f[1].(string)
+		if !ok1 || !ok2 {
+			return nil, errors.New("invalid fenced entry")
+		}
+		l.fenced[rune(cp)] = name
+	}
+
+	for i, g := range s.Groups {
+		l.groups = append(l.groups, &scriptGroup{
+			index:      i,
+			name:       g.Name,
+			restricted: g.Restricted,
+			cm:         g.CM,
+			primary:    newRuneSet(g.Primary),
+			secondary:  newRuneSet(g.Secondary),
+		})
+		if g.Name == "Greek" {
+			l.greek = l.groups[i]
+		}
+	}
+
+	l.parseEmoji(s.Emoji)
+
+	// valid characters are the members of any group and their decompositions.
+	// Characters in exactly one group that are not confusable are enough to
+	// rule out a whole-script confusable.
+	var sets []runeSet
+	for _, g := range l.groups {
+		sets = append(sets, g.primary, g.secondary)
+	}
+	union, single := coverage(sets)
+
+	decomposed := make(map[rune]bool)
+	for _, r := range union {
+		for cp := r.lo; cp <= r.hi; cp++ {
+			if _, ok := l.nf.decomps[cp]; ok || isHangulSyllable(cp) {
+				for _, d := range l.nf.NFD([]rune{cp}) {
+					decomposed[d] = true
+				}
+			}
+		}
+	}
+	l.valid, _ = coverage([]runeSet{union, runeSetFromMap(decomposed)})
+
+	for _, w := range s.Wholes {
+		l.parseWhole(newRuneSet(w.Valid), newRuneSet(w.Confused))
+	}
+
+	l.uniqueValids = single.without(l.confusables)
+
+	return l, nil
+}
+
+func (l *ensip15) parseEmoji(sequences [][]rune) {
+	emoji := make([]*emojiSequence, len(sequences))
+	for i, beautified := range sequences {
+		normalized := make([]rune, 0, len(beautified))
+		for _, cp := range beautified {
+			if cp != fe0f {
+				normalized = append(normalized, cp)
+			}
+		}
+		emoji[i] = &emojiSequence{normalized, beautified}
+	}
+
+	sort.SliceStable(emoji, func(i, j int) bool {
+		a, b := emoji[i].normalized, emoji[j].normalized
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	// FE0F is optional in the input, so every node before a FE0F is also
+	// linked to the node after it
+	for _, e := range emoji {
+		nodes := []*emojiNode{l.emojiRoot}
+		for _, cp := range e.beautified {
+			if cp == fe0f {
+				for _, n := range nodes {
+					nodes = append(nodes, n.child(cp))
+				}
+				continue
+			}
+			for i, n := range nodes {
+				nodes[i] = n.child(cp)
+			}
+		}
+		for _, n := range nodes {
+			n.emoji = e
+		}
+	}
+}
+
+// parseWhole records, for each confusable character of the whole, the groups
+// that have a look-alike of it but are not among the groups of the character
+func (l *ensip15) parseWhole(valid runeSet, confused runeSet) {
+	type extent struct {
+		groups map[int]bool
+		cps    []rune
+	}
+
+	var extents []*extent
+	cover := make(map[int]bool)
+	add := func(cp rune) {
+		groups := make(map[int]bool)
+		for _, g := range l.groups {
+			if g.contains(cp) {
+				groups[g.index] = true
+			}
+		}
+
+		var ext *extent
+	find:
+		for _, x := range extents {
+			for g := range groups {
+				if x.groups[g] {
+					ext = x
+					break find
+				}
+			}
+		}
+		if ext == nil {
+			ext = &extent{groups: make(map[int]bool)}
+			extents = append(extents, ext)
+		}
+		for g := range groups {
+			ext.groups[g] = true
+			cover[g] = true
+		}
+		ext.cps = append(ext.cps, cp)
+	}
+	valid.each(add)
+	confused.each(add)
+
+	for _, x := range extents {
+		complement := []int{}
+		for g := range cover {
+			if !x.groups[g] {
+				complement = append(complement, g)
+			}
+		}
+		sort.Ints(complement)
+		for _, cp := range x.cps {
+			if confused.contains(cp) {
+				l.confusables[cp] = complement
+			}
+		}
+	}
+}
+
+// token is either an emoji or a run of text
+type token struct {
+	cps   []rune
+	emoji *emojiSequence
+}
+
+func (l *ensip15) parseEmojiAt(cps []rune, pos int) (emoji *emojiSequence, end int) {
+	node := l.emojiRoot
+	for pos < len(cps) {
+		if node = node.children[cps[pos]]; node == nil {
+			break
+		}
+		pos++
+		if node.emoji != nil {
+			emoji, end = node.emoji, pos
+		}
+	}
+	return emoji, end
+}
+
+func (l *ensip15) tokenize(cps []rune, beautify bool) ([]token, error) {
+	var tokens []token
+	var text []rune
+
+	flush := func() {
+		if len(text) > 0 {
+			tokens = append(tokens, token{cps: l.nf.NFC(text)})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(cps); {
+		if emoji, end := l.parseEmojiAt(cps, i); emoji != nil {
+			flush()
+			out := emoji.normalized
+			if beautify {
+				out = emoji.beautified
+			}
+			tokens = append(tokens, token{out, emoji})
+			i = end
+			continue
+		}
+
+		cp := cps[i]
+		if l.valid.contains(cp) {
+			text = append(text, cp)
+		} else if mapped, ok := l.mapped[cp]; ok {
+			text = append(text, mapped...)
+		} else if !l.ignored.contains(cp) {
+			return nil, fmt.Errorf("%w: %s", ErrDisallowedCharacter, l.safeCodepoint(cp))
+		}
+		i++
+	}
+	flush()
+
+	return tokens, nil
+}
+
+func (l *ensip15) transform(name string, beautify bool) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	labels := strings.Split(name, string(labelStop))
+	for i, label := range labels {
+		tokens, err := l.tokenize([]rune(label), beautify)
+		if err != nil {
+			return "", fmt.Errorf("invalid label %q: %w", label, err)
+		}
+
+		var cps []rune
+		for _, t := range tokens {
+			cps = append(cps, t.cps...)
+		}
+
+		group, err := l.checkLabel(cps, tokens)
+		if err != nil {
+			return "", fmt.Errorf("invalid label %q: %w", label, err)
+		}
+
+		if beautify && group != l.greek {
+			for j, cp := range cps {
+				if cp == greekSmallXi {
+					cps[j] = greekCapitalXi
+				}
+			}
+		}
+
+		labels[i] = string(cps)
+	}
+
+	return strings.Join(labels, string(labelStop)), nil
+}
+
+// checkLabel validates the normalized label and returns its script group,
+// which is nil for ASCII and emoji-only labels
+func (l *ensip15) checkLabel(cps []rune, tokens []token) (*scriptGroup, error) {
+	if len(cps) == 0 {
+		return nil, ErrEmptyLabel
+	}
+
+	// underscores are only allowed at the start
+	for i := len(cps) - 1; i > 0; i-- {
+		if cps[i] == underscore {
+			for j := i - 1; j >= 0; j-- {
+				if cps[j] != underscore {
+					return nil, ErrLeadingUnderscore
+				}
+			}
+			break
+		}
+	}
+
+	hasEmoji := len(tokens) > 1 || tokens[0].emoji != nil
+	if !hasEmoji && isASCII(cps) {
+		if len(cps) >= 4 && cps[2] == hyphen && cps[3] == hyphen {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLabelExtension, string(cps[:4]))
+		}
+		return nil, nil
+	}
+
+	var chars []rune
+	for _, t := range tokens {
+		if t.emoji == nil {
+			chars = append(chars, t.cps...)
+		}
+	}
+	if len(chars) == 0 {
+		return nil, nil
+	}
+
+	for i, t := range tokens {
+		if t.emoji == nil && l.cm.contains(t.cps[0]) {
+			if i == 0 {
+				return nil, fmt.Errorf("%w: %s", ErrLeadingCombiningMark, l.safeCodepoint(t.cps[0]))
+			}
+			return nil, fmt.Errorf("%w: %s + %s", ErrEmojiCombiningMark, string(tokens[i-1].emoji.beautified), l.safeCodepoint(t.cps[0]))
+		}
+	}
+
+	if err := l.checkFenced(cps); err != nil {
+		return nil, err
+	}
+
+	unique := uniqueRunes(chars)
+	group, err := l.determineGroup(unique)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.checkGroup(group, chars); err != nil {
+		return nil, err
+	}
+	if err := l.checkWhole(group, unique); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (l *ensip15) checkFenced(cps []rune) error {
+	if name, ok := l.fenced[cps[0]]; ok {
+		return fmt.Errorf("%w: %s", ErrFencedLeading, name)
+	}
+
+	last := -1
+	var lastName string
+	for i := 1; i < len(cps); i++ {
+		name, ok := l.fenced[cps[i]]
+		if !ok {
+			continue
+		}
+		if last == i {
+			return fmt.Errorf("%w: %s + %s", ErrFencedAdjacent, lastName, name)
+		}
+		last = i + 1
+		lastName = name
+	}
+	if last == len(cps) {
+		return fmt.Errorf("%w: %s", ErrFencedTrailing, lastName)
+	}
+
+	return nil
+}
+
+func (l *ensip15) determineGroup(unique []rune) (*scriptGroup, error) {
+	groups := l.groups
+	for _, cp := range unique {
+		var next []*scriptGroup
+		for _, g := range groups {
+			if g.contains(cp) {
+				next = append(next, g)
+			}
+		}
+		if len(next) == 0 {
+			for _, g := range l.groups {
+				if g.contains(cp) {
+					return nil, l.mixtureError(groups[0], cp)
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrDisallowedCharacter, l.safeCodepoint(cp))
+		}
+		if groups = next; len(groups) == 1 {
+			break
+		}
+	}
+	return groups[0], nil
+}
+
+func (l *ensip15) checkGroup(group *scriptGroup, chars []rune) error {
+	for _, cp := range chars {
+		if !group.contains(cp) {
+			return l.mixtureError(group, cp)
+		}
+	}
+	if group.cm {
+		return nil
+	}
+
+	// limit the number of non-spacing marks in a row and disallow repeats
+	decomposed := l.nf.NFD(chars)
+	for i := 1; i < len(decomposed); i++ {
+		if !l.nsm.contains(decomposed[i]) {
+			continue
+		}
+		j := i + 1
+		for ; j < len(decomposed) && l.nsm.contains(decomposed[j]); j++ {
+			for k := i; k < j; k++ {
+				if decomposed[k] == decomposed[j] {
+					return fmt.Errorf("%w: %s", ErrDuplicateNSM, l.safeCodepoint(decomposed[j]))
+				}
+			}
+		}
+		if j-i > l.nsmMax {
+			return fmt.Errorf("%w: %s (%d/%d)", ErrExcessiveNSM, l.safeString(decomposed[i-1:j]), j-i, l.nsmMax)
+		}
+		i = j
+	}
+
+	return nil
+}
+
+// checkWhole rejects labels made only of characters that have look-alikes in
+// another single group, such that the whole label could be spelled in it
+func (l *ensip15) checkWhole(group *scriptGroup, unique []rune) error {
+	var shared []rune
+	var candidates []int
+	first := true
+	for _, cp := range unique {
+		complement, ok := l.confusables[cp]
+		if !ok {
+			if l.uniqueValids.contains(cp) {
+				return nil
+			}
+			shared = append(shared, cp)
+			continue
+		}
+
+		if first {
+			candidates = append([]int{}, complement...)
+			first = false
+		} else {
+			next := candidates[:0]
+			for _, g := range candidates {
+				if i := sort.SearchInts(complement, g); i < len(complement) && complement[i] == g {
+					next = append(next, g)
+				}
+			}
+			candidates = next
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+next:
+	for _, g := range candidates {
+		other := l.groups[g]
+		for _, cp := range shared {
+			if !other.contains(cp) {
+				continue next
+			}
+		}
+		return fmt.Errorf("%w: %s/%s", ErrWholeScriptConfusable, group, other)
+	}
+
+	return nil
+}
+
+func (l *ensip15) mixtureError(group *scriptGroup, cp rune) error {
+	conflict := l.safeCodepoint(cp)
+	for _, g := range l.groups {
+		if g.primary.contains(cp) {
+			conflict = fmt.Sprintf("%s %s", g, conflict)
+			break
+		}
+	}
+	return fmt.Errorf("%w: %s + %s", ErrIllegalMixture, group, conflict)
+}
+
+// safeCodepoint formats a codepoint for error messages, like "a" {61}
+func (l *ensip15) safeCodepoint(cp rune) string {
+	if l.escape.contains(cp) {
+		return fmt.Sprintf("{%02X}", cp)
+	}
+	return fmt.Sprintf("%q {%02X}", l.safeString([]rune{cp}), cp)
+}
+
+func (l *ensip15) safeString(cps []rune) string {
+	var sb strings.Builder
+	if len(cps) > 0 && l.cm.contains(cps[0]) {
+		sb.WriteRune(0x25CC) // dotted circle
+	}
+	for _, cp := range cps {
+		if l.escape.contains(cp) {
+			fmt.Fprintf(&sb, "{%02X}", cp)
+		} else {
+			sb.WriteRune(cp)
+		}
+	}
+	return sb.String()
+}
+
+func isASCII(cps []rune) bool {
+	for _, cp := range cps {
+		if cp >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueRunes(cps []rune) []rune {
+	seen := make(map[rune]bool, len(cps))
+	unique := make([]rune, 0, len(cps))
+	for _, cp := range cps {
+		if !seen[cp] {
+			seen[cp] = true
+			unique = append(unique, cp)
+		}
+	}
+	return unique
+}
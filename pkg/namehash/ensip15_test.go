@@ -0,0 +1,134 @@
+package namehash
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readTestData(t *testing.T, path string, v interface{}) {
+	f, err := os.Open(path)
+	require.Nil(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.Nil(t, err)
+	require.Nil(t, json.NewDecoder(zr).Decode(v))
+}
+
+func hexRunes(s string) string {
+	return fmt.Sprintf("%U", []rune(s))
+}
+
+// TestENSIP15 checks Normalize against the official ENSIP-15 validation tests
+// https://github.com/adraffy/ens-normalize.js/tree/main/validate
+func TestENSIP15(t *testing.T) {
+	var tests []struct {
+		Name    string  `json:"name"`
+		Norm    *string `json:"norm"`
+		Error   bool    `json:"error"`
+		Comment string  `json:"comment"`
+		Unicode string  `json:"unicode"`
+	}
+	readTestData(t, "testdata/tests.json.gz", &tests)
+	require.NotEmpty(t, tests)
+
+	// the first entry describes the versions the tests were generated with
+	require.Equal(t, "version", tests[0].Name)
+	require.Equal(t, tests[0].Unicode, loadSpec().unicode)
+
+	failures := 0
+	for _, test := range tests[1:] {
+		norm, err := Normalize(test.Name)
+
+		switch {
+		case test.Error:
+			if err == nil {
+				t.Errorf("%s (%s): expected an error, got %s", hexRunes(test.Name), test.Comment, hexRunes(norm))
+				failures++
+			}
+		case err != nil:
+			t.Errorf("%s (%s): unexpected error: %v", hexRunes(test.Name), test.Comment, err)
+			failures++
+		default:
+			expected := test.Name
+			if test.Norm != nil {
+				expected = *test.Norm
+			}
+			if norm != expected {
+				t.Errorf("%s (%s): expected %s, got %s", hexRunes(test.Name), test.Comment, hexRunes(expected), hexRunes(norm))
+				failures++
+			}
+		}
+
+		if failures > 20 {
+			t.Fatal("too many failures")
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		norm string
+	}{
+		{"", ""},
+		{"eth", "eth"},
+		{"TeSt.ETH", "test.eth"},
+		{"RaFFY🚴‍♂️.eTh", "raffy🚴‍♂.eth"},
+		{"1️⃣2️⃣.eth", "1⃣2⃣.eth"},
+		{"_test.eth", "_test.eth"},
+		{"ⓐⓑⓒ.eth", "abc.eth"},
+		{"ξ.eth", "ξ.eth"},
+		{"café.eth", "café.eth"},
+	} {
+		norm, err := Normalize(tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.norm, norm, tc.name)
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{".eth", ErrEmptyLabel},
+		{"test..eth", ErrEmptyLabel},
+		{"te_st.eth", ErrLeadingUnderscore},
+		{"xn--test.eth", ErrInvalidLabelExtension},
+		{"a b.eth", ErrDisallowedCharacter},
+		{"*.eth", ErrDisallowedCharacter},
+		{"́a.eth", ErrLeadingCombiningMark},
+		{"’a.eth", ErrFencedLeading},
+		{"a’’a.eth", ErrFencedAdjacent},
+		{"a’.eth", ErrFencedTrailing},
+		{"aα.eth", ErrIllegalMixture},
+		{"аррӏе.eth", ErrWholeScriptConfusable},
+	} {
+		_, err := Normalize(tc.name)
+		require.True(t, errors.Is(err, tc.err), "%s: %v", tc.name, err)
+	}
+}
+
+func TestBeautify(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		beautiful string
+	}{
+		{"1⃣2⃣.eth", "1️⃣2️⃣.eth"},
+		{"ξabc.eth", "Ξabc.eth"},
+		{"ξλφα.eth", "ξλφα.eth"},
+		{"TEST.eth", "test.eth"},
+	} {
+		beautiful, err := Beautify(tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.beautiful, beautiful, tc.name)
+	}
+
+	_, err := Beautify("a..eth")
+	require.True(t, errors.Is(err, ErrEmptyLabel))
+}
@@ -0,0 +1,190 @@
+//go:build ignore
+
+// gen.go converts the ENSIP-15 spec.json and nf.json published with the
+// reference implementation into the compact form embedded by this package.
+// Sets of codepoints are encoded as ranges, [gap, length, gap, length, ...],
+// where each gap is relative to the end of the previous range.
+//
+// https://github.com/adraffy/ens-normalize.js/tree/main/derive/output (MIT License)
+//
+//	go run gen.go -spec spec.json -nf nf.json
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"sort"
+)
+
+type spec struct {
+	Created  string          `json:"created"`
+	Unicode  string          `json:"unicode"`
+	Emoji    [][]int         `json:"emoji"`
+	Ignored  []int           `json:"ignored"`
+	Mapped   [][]interface{} `json:"mapped"`
+	Fenced   [][]interface{} `json:"fenced"`
+	Wholes   []whole         `json:"wholes"`
+	CM       []int           `json:"cm"`
+	NSM      []int           `json:"nsm"`
+	NSMMax   int             `json:"nsm_max"`
+	Escape   []int           `json:"escape"`
+	Groups   []group         `json:"groups"`
+	NFCCheck []int           `json:"nfc_check"`
+}
+
+type whole struct {
+	Valid    []int `json:"valid"`
+	Confused []int `json:"confused"`
+}
+
+type group struct {
+	Name       string      `json:"name"`
+	Restricted bool        `json:"restricted"`
+	CM         interface{} `json:"cm"`
+	Primary    []int       `json:"primary"`
+	Secondary  []int       `json:"secondary"`
+}
+
+type nf struct {
+	Unicode    string          `json:"unicode"`
+	Ranks      [][]int         `json:"ranks"`
+	Exclusions []int           `json:"exclusions"`
+	Decomp     [][]interface{} `json:"decomp"`
+}
+
+type compactWhole struct {
+	Valid    []int `json:"valid"`
+	Confused []int `json:"confused"`
+}
+
+type compactGroup struct {
+	Name       string `json:"name"`
+	Restricted bool   `json:"restricted,omitempty"`
+	CM         bool   `json:"cm,omitempty"`
+	Primary    []int  `json:"primary"`
+	Secondary  []int  `json:"secondary"`
+}
+
+type compactNF struct {
+	Ranks      [][]int         `json:"ranks"`
+	Exclusions []int           `json:"exclusions"`
+	Decomp     [][]interface{} `json:"decomp"`
+}
+
+type compactSpec struct {
+	Unicode  string          `json:"unicode"`
+	SpecHash string          `json:"spec_hash"`
+	Emoji    [][]int         `json:"emoji"`
+	Ignored  []int           `json:"ignored"`
+	Mapped   [][]interface{} `json:"mapped"`
+	Fenced   [][]interface{} `json:"fenced"`
+	Wholes   []compactWhole  `json:"wholes"`
+	CM       []int           `json:"cm"`
+	NSM      []int           `json:"nsm"`
+	NSMMax   int             `json:"nsm_max"`
+	Escape   []int           `json:"escape"`
+	Groups   []compactGroup  `json:"groups"`
+	NF       compactNF       `json:"nf"`
+}
+
+func ranges(cps []int) []int {
+	sorted := append([]int{}, cps...)
+	sort.Ints(sorted)
+
+	out := []int{}
+	next := 0
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+		out = append(out, sorted[i]-next, sorted[j]-sorted[i]+1)
+		next = sorted[j] + 1
+		i = j + 1
+	}
+	return out
+}
+
+func readJSON(path string, v interface{}) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	return data
+}
+
+func main() {
+	specPath := flag.String("spec", "spec.json", "path to spec.json")
+	nfPath := flag.String("nf", "nf.json", "path to nf.json")
+	outPath := flag.String("out", "data/ensip15.json.gz", "output path")
+	flag.Parse()
+
+	var s spec
+	specData := readJSON(*specPath, &s)
+	var n nf
+	readJSON(*nfPath, &n)
+
+	if s.Unicode != n.Unicode {
+		log.Fatalf("unicode version mismatch: %s vs %s", s.Unicode, n.Unicode)
+	}
+
+	hash := sha256.Sum256(specData)
+	c := compactSpec{
+		Unicode:  s.Unicode,
+		SpecHash: hex.EncodeToString(hash[:]),
+		Emoji:    s.Emoji,
+		Ignored:  ranges(s.Ignored),
+		Mapped:   s.Mapped,
+		Fenced:   s.Fenced,
+		CM:       ranges(s.CM),
+		NSM:      ranges(s.NSM),
+		NSMMax:   s.NSMMax,
+		Escape:   ranges(s.Escape),
+		NF: compactNF{
+			Exclusions: ranges(n.Exclusions),
+			Decomp:     n.Decomp,
+		},
+	}
+	for _, w := range s.Wholes {
+		c.Wholes = append(c.Wholes, compactWhole{ranges(w.Valid), ranges(w.Confused)})
+	}
+	for _, g := range s.Groups {
+		c.Groups = append(c.Groups, compactGroup{
+			Name:       g.Name,
+			Restricted: g.Restricted,
+			// the reference implementation treats the presence of "cm" as
+			// the group allowing any sequence of combining marks
+			CM:        g.CM != nil,
+			Primary:   ranges(g.Primary),
+			Secondary: ranges(g.Secondary),
+		})
+	}
+	for _, r := range n.Ranks {
+		c.NF.Ranks = append(c.NF.Ranks, ranges(r))
+	}
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	zw, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.NewEncoder(zw).Encode(&c); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
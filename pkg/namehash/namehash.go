@@ -15,31 +15,37 @@
 package namehash
 
 import (
+	"encoding/hex"
 	"strings"
 
-	"golang.org/x/net/idna"
-
 	"golang.org/x/crypto/sha3"
 )
 
-var p = idna.New(idna.MapForLookup(), idna.StrictDomainName(false), idna.Transitional(false))
-var pStrict = idna.New(idna.MapForLookup(), idna.StrictDomainName(true), idna.Transitional(false))
-
-// Normalize normalizes a name according to the ENS rules
+// Normalize normalizes a name according to ENSIP-15
+// https://docs.ens.domains/ensip/15
 func Normalize(input string) (output string, err error) {
-	output, err = p.ToUnicode(input)
-	if err != nil {
-		return
-	}
-	// If the name started with a period then ToUnicode() removes it, but we want to keep it
-	if strings.HasPrefix(input, ".") && !strings.HasPrefix(output, ".") {
-		output = "." + output
-	}
-	return
+	return loadSpec().transform(input, false)
+}
+
+// Beautify normalizes a name like Normalize, but keeps the emoji presentation
+// selectors (FE0F) and uses Ξ rather than ξ outside of Greek labels, for
+// display
+func Beautify(input string) (output string, err error) {
+	return loadSpec().transform(input, true)
 }
 
-// LabelHash generates a simple hash for a piece of a name.
+// LabelHash generates a simple hash for a piece of a name. Encoded labels,
+// the label hash of a label whose preimage is unknown in hex between square
+// brackets, return the label hash they hold.
 func LabelHash(label string) (hash [32]byte, err error) {
+	if hash, ok := DecodeLabelHash(label); ok {
+		return hash, nil
+	}
+	if label == "" {
+		err = ErrEmptyLabel
+		return
+	}
+
 	normalizedLabel, err := Normalize(label)
 	if err != nil {
 		return
@@ -59,11 +65,7 @@ func NameHash(name string) (hash [32]byte, err error) {
 	if name == "" {
 		return
 	}
-	normalizedName, err := Normalize(name)
-	if err != nil {
-		return
-	}
-	parts := strings.Split(normalizedName, ".")
+	parts := strings.Split(name, ".")
 	for i := len(parts) - 1; i >= 0; i-- {
 		if hash, err = nameHashPart(hash, parts[i]); err != nil {
 			return
@@ -72,19 +74,50 @@ func NameHash(name string) (hash [32]byte, err error) {
 	return
 }
 
-func nameHashPart(currentHash [32]byte, name string) (hash [32]byte, err error) {
-	sha := sha3.NewLegacyKeccak256()
-	if _, err = sha.Write(currentHash[:]); err != nil {
+func nameHashPart(currentHash [32]byte, label string) (hash [32]byte, err error) {
+	labelHash, err := LabelHash(label)
+	if err != nil {
 		return
 	}
-	nameSha := sha3.NewLegacyKeccak256()
-	if _, err = nameSha.Write([]byte(name)); err != nil {
+	sha := sha3.NewLegacyKeccak256()
+	if _, err = sha.Write(currentHash[:]); err != nil {
 		return
 	}
-	nameHash := nameSha.Sum(nil)
-	if _, err = sha.Write(nameHash); err != nil {
+	if _, err = sha.Write(labelHash[:]); err != nil {
 		return
 	}
 	sha.Sum(hash[:0])
 	return
 }
+
+// EncodeLabelHash returns the encoded label for a label hash, like
+// "[4f5b812789fc606be1b3b16908db13fc7a9adf7ca72641f84d75b47069d3d7f0]"
+func EncodeLabelHash(hash [32]byte) string {
+	return "[" + hex.EncodeToString(hash[:]) + "]"
+}
+
+// DecodeLabelHash returns the label hash held by an encoded label. A 0x
+// prefix inside the brackets is accepted.
+func DecodeLabelHash(label string) (hash [32]byte, ok bool) {
+	if len(label) < 2 || label[0] != '[' || label[len(label)-1] != ']' {
+		return
+	}
+	hexHash := strings.TrimPrefix(label[1:len(label)-1], "0x")
+	if len(hexHash) != 64 {
+		return
+	}
+	if _, err := hex.Decode(hash[:], []byte(hexHash)); err != nil {
+		return [32]byte{}, false
+	}
+	return hash, true
+}
+
+// HasEncodedLabels reports whether any label of the name is an encoded label
+func HasEncodedLabels(name string) bool {
+	for _, label := range strings.Split(name, ".") {
+		if _, ok := DecodeLabelHash(label); ok {
+			return true
+		}
+	}
+	return false
+}
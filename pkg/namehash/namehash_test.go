@@ -0,0 +1,104 @@
+package namehash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameHash(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hash string
+	}{
+		{"", "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		// names are normalized before hashing
+		{"FOO.eth", "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	} {
+		hash, err := NameHash(tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.hash, hexutil.Encode(hash[:]), tc.name)
+	}
+
+	// emoji presentation selectors are not part of the normalized name
+	hash1, err := NameHash("1️⃣.eth")
+	require.Nil(t, err)
+	hash2, err := NameHash("1⃣.eth")
+	require.Nil(t, err)
+	require.Equal(t, hash1, hash2)
+
+	_, err = NameHash("foo..eth")
+	require.NotNil(t, err)
+}
+
+func TestLabelHash(t *testing.T) {
+	hash, err := LabelHash("eth")
+	require.Nil(t, err)
+	require.Equal(t, "0x4f5b812789fc606be1b3b16908db13fc7a9adf7ca72641f84d75b47069d3d7f0", hexutil.Encode(hash[:]))
+
+	_, err = LabelHash("a b")
+	require.NotNil(t, err)
+}
+
+func BenchmarkParseSpec(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := parseSpec(ensip15Data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEncodedLabels(t *testing.T) {
+	labelHash, err := LabelHash("foo")
+	require.Nil(t, err)
+
+	encoded := EncodeLabelHash(labelHash)
+	require.Equal(t, "[41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4d]", encoded)
+
+	decoded, ok := DecodeLabelHash(encoded)
+	require.True(t, ok)
+	require.Equal(t, labelHash, decoded)
+
+	hash, err := LabelHash(encoded)
+	require.Nil(t, err)
+	require.Equal(t, labelHash, hash)
+
+	// the 0x prefix and upper case hex are accepted
+	hash, err = LabelHash("[0x41B1A0649752AF1B28B3DC29A1556EEE781E4A4C3A1F7F53F90FA834DE098C4D]")
+	require.Nil(t, err)
+	require.Equal(t, labelHash, hash)
+
+	nameHash, err := NameHash(encoded + ".eth")
+	require.Nil(t, err)
+	expected, err := NameHash("foo.eth")
+	require.Nil(t, err)
+	require.Equal(t, expected, nameHash)
+
+	require.True(t, HasEncodedLabels("bar."+encoded+".eth"))
+	require.False(t, HasEncodedLabels("foo.eth"))
+
+	for _, label := range []string{
+		"[]",
+		"[41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4]",
+		"[41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4dd]",
+		"[41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4z]",
+		"41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4d",
+	} {
+		_, ok := DecodeLabelHash(label)
+		require.False(t, ok, label)
+
+		// anything else in brackets is not a valid label
+		_, err := LabelHash(label)
+		if label[0] == '[' {
+			require.NotNil(t, err, label)
+		}
+	}
+
+	// encoded labels are not normalizable names
+	_, err = Normalize(encoded + ".eth")
+	require.True(t, errors.Is(err, ErrDisallowedCharacter))
+}
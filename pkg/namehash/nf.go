@@ -0,0 +1,129 @@
+package namehash
+
+// Unicode normalization forms NFD and NFC, implemented with the tables derived
+// for ENSIP-15 so that they match the Unicode version of the spec rather than
+// the one of the Go toolchain.
+// https://www.unicode.org/reports/tr15/
+
+// Hangul syllable constants, https://www.unicode.org/versions/latest/ch03.pdf
+const (
+	hangulS0     = 0xAC00
+	hangulL0     = 0x1100
+	hangulV0     = 0x1161
+	hangulT0     = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+type normalizer struct {
+	// decomps maps a codepoint to its canonical decomposition
+	decomps map[rune][]rune
+	// recomps maps a pair of codepoints to their primary composite
+	recomps map[[2]rune]rune
+	// ranks maps non-starters to the order of their canonical combining class
+	ranks map[rune]int
+}
+
+func isHangulSyllable(cp rune) bool {
+	return cp >= hangulS0 && cp < hangulS0+hangulSCount
+}
+
+func (n *normalizer) decompose(cps []rune) []rune {
+	out := make([]rune, 0, len(cps))
+
+	var decompose func(cp rune)
+	decompose = func(cp rune) {
+		if cp < 0x80 {
+			out = append(out, cp)
+			return
+		}
+
+		if isHangulSyllable(cp) {
+			s := cp - hangulS0
+			out = append(out, hangulL0+s/hangulNCount, hangulV0+(s%hangulNCount)/hangulTCount)
+			if t := s % hangulTCount; t > 0 {
+				out = append(out, hangulT0+t)
+			}
+			return
+		}
+
+		decomp, ok := n.decomps[cp]
+		if !ok {
+			out = append(out, cp)
+			return
+		}
+		for _, cp := range decomp {
+			decompose(cp)
+		}
+	}
+
+	for _, cp := range cps {
+		decompose(cp)
+	}
+
+	// canonical ordering: stable sort each run of non-starters by rank
+	for i := 1; i < len(out); i++ {
+		rank := n.ranks[out[i]]
+		if rank == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			prev := n.ranks[out[j-1]]
+			if prev == 0 || prev <= rank {
+				break
+			}
+			out[j-1], out[j] = out[j], out[j-1]
+		}
+	}
+
+	return out
+}
+
+func (n *normalizer) composePair(a, b rune) (rune, bool) {
+	switch {
+	case a >= hangulL0 && a < hangulL0+hangulLCount && b >= hangulV0 && b < hangulV0+hangulVCount:
+		return hangulS0 + ((a-hangulL0)*hangulVCount+(b-hangulV0))*hangulTCount, true
+	case isHangulSyllable(a) && (a-hangulS0)%hangulTCount == 0 && b > hangulT0 && b < hangulT0+hangulTCount:
+		return a + (b - hangulT0), true
+	}
+	cp, ok := n.recomps[[2]rune{a, b}]
+	return cp, ok
+}
+
+// NFD returns the canonical decomposition of cps
+func (n *normalizer) NFD(cps []rune) []rune {
+	return n.decompose(cps)
+}
+
+// NFC returns the canonical composition of cps
+func (n *normalizer) NFC(cps []rune) []rune {
+	decomposed := n.decompose(cps)
+	out := decomposed[:0]
+
+	starter := -1
+	lastRank := 0
+	for _, cp := range decomposed {
+		rank := n.ranks[cp]
+		if starter >= 0 {
+			// a character is blocked from the starter if there is a character
+			// in between with a rank of zero, or not lower than its own
+			adjacent := starter == len(out)-1
+			if adjacent || (lastRank != 0 && lastRank < rank) {
+				if composed, ok := n.composePair(out[starter], cp); ok {
+					out[starter] = composed
+					continue
+				}
+			}
+		}
+		if rank == 0 {
+			starter = len(out)
+		}
+		out = append(out, cp)
+		lastRank = rank
+	}
+
+	return out
+}
@@ -0,0 +1,30 @@
+package namehash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNF checks NFD and NFC against the Unicode normalization tests published
+// with ENSIP-15
+func TestNF(t *testing.T) {
+	var tests map[string]interface{}
+	readTestData(t, "testdata/nf-tests.json.gz", &tests)
+
+	nf := loadSpec().nf
+	for name, value := range tests {
+		cases, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		require.NotEmpty(t, cases, name)
+
+		for _, c := range cases {
+			v := c.([]interface{})
+			input := []rune(v[0].(string))
+			require.Equal(t, v[1].(string), string(nf.NFD(input)), "%s: NFD %U", name, input)
+			require.Equal(t, v[2].(string), string(nf.NFC(input)), "%s: NFC %U", name, input)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package textrecord validates the values of text records returned by
+// text(bytes32,string), with a catalogue of the ENSIP-5 keys.
+//
+// https://docs.ens.domains/ensip/5
+package textrecord
+
+import (
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/avatar"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxValueSize is the default maximum size of a value in bytes. It does
+// not apply to avatars, whose ENSIP-12 data: URIs often embed larger images.
+const DefaultMaxValueSize = 8 * 1024
+
+// ENSIP-5 global keys
+const (
+	KeyAvatar      = "avatar"
+	KeyDescription = "description"
+	KeyDisplay     = "display"
+	KeyEmail       = "email"
+	KeyKeywords    = "keywords"
+	KeyMail        = "mail"
+	KeyNotice      = "notice"
+	KeyLocation    = "location"
+	KeyPhone       = "phone"
+	KeyURL         = "url"
+)
+
+// ENSIP-5 service keys
+const (
+	KeyGitHub   = "com.github"
+	KeyPeepeth  = "com.peepeth"
+	KeyLinkedIn = "com.linkedin"
+	KeyTwitter  = "com.twitter"
+	KeyKeybase  = "io.keybase"
+	KeyTelegram = "org.telegram"
+)
+
+// Validator checks the value of a text record. Empty values, meaning the
+// record is not set, are not passed to validators.
+type Validator func(value string) error
+
+// service keys are in reverse-DNS notation, e.g. com.example.users
+var serviceKeyRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// Registry holds the known keys and their validators
+type Registry struct {
+	mu           sync.RWMutex
+	validators   map[string]Validator
+	maxValueSize int
+}
+
+// DefaultRegistry is used by Validate and has the ENSIP-5 keys registered
+var DefaultRegistry = NewDefaultRegistry()
+
+// NewRegistry returns a registry without any keys
+func NewRegistry() *Registry {
+	return &Registry{validators: make(map[string]Validator), maxValueSize: DefaultMaxValueSize}
+}
+
+// NewDefaultRegistry returns a new registry with the ENSIP-5 keys registered
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	for _, key := range []string{
+		KeyDescription, KeyDisplay, KeyKeywords, KeyMail, KeyNotice, KeyLocation, KeyPhone,
+		KeyPeepeth, KeyLinkedIn, KeyKeybase, KeyTelegram,
+	} {
+		r.validators[key] = nil
+	}
+	r.validators[KeyAvatar] = avatar.Validate
+	r.validators[KeyEmail] = validateEmail
+	r.validators[KeyURL] = validateURL
+	r.validators[KeyGitHub] = validateGitHubUsername
+	r.validators[KeyTwitter] = validateTwitterUsername
+
+	return r
+}
+
+// Register adds a key to the registry, replacing any validator previously
+// registered for it. New keys must be service keys in reverse-DNS notation,
+// like "com.example.users". validate may be nil if any value is allowed.
+func (r *Registry) Register(key string, validate Validator) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.validators[key]; !ok && !serviceKeyRegexp.MatchString(key) {
+		return errors.Errorf("key %q must be in reverse-DNS notation", key)
+	}
+	r.validators[key] = validate
+
+	return nil
+}
+
+// SetMaxValueSize sets the maximum size of a value in bytes, for all the keys
+// but the avatar
+func (r *Registry) SetMaxValueSize(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxValueSize = size
+}
+
+// Known reports whether the key is registered
+func (r *Registry) Known(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.validators[key]
+	return ok
+}
+
+// Validate checks that the value is valid UTF-8 and not too large, and that it
+// is valid for the key if the key is registered with a validator. Values of
+// unknown keys are not checked further. Avatars are not limited in size.
+func (r *Registry) Validate(key string, value []byte) error {
+	r.mu.RLock()
+	validate, maxValueSize := r.validators[key], r.maxValueSize
+	r.mu.RUnlock()
+
+	if !utf8.Valid(value) {
+		return errors.Errorf("value of %q is not valid UTF-8", key)
+	}
+	if len(value) > maxValueSize && key != KeyAvatar {
+		return errors.Errorf("value of %q must be at most %d bytes long", key, maxValueSize)
+	}
+
+	if validate == nil || len(value) == 0 {
+		return nil
+	}
+	if err := validate(string(value)); err != nil {
+		return errors.Wrapf(err, "invalid value for %q", key)
+	}
+	return nil
+}
+
+// Validate checks the value of a text record using the default registry
+func Validate(key string, value []byte) error {
+	return DefaultRegistry.Validate(key, value)
+}
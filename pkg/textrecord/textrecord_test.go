@@ -0,0 +1,94 @@
+package textrecord
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		key   string
+		value string
+		err   string
+	}{
+		{KeyAvatar, "https://example.com/avatar.png", ""},
+		{KeyAvatar, "eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/2430", ""},
+		{KeyAvatar, "ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4", ""},
+		{KeyAvatar, "data:image/png;base64,iVBORw0KGgo=", ""},
+		{KeyAvatar, "avatar.png", `invalid value for "avatar": avatar must be a URI`},
+		{KeyAvatar, "eip155:1/erc721:0xb7f7f6c52f2e2fdb1963eab30438024864c313f6/abc", `invalid value for "avatar": invalid token ID in NFT avatar`},
+		{KeyURL, "https://example.com", ""},
+		{KeyURL, "ftp://example.com", `invalid value for "url": value must be an http or https URL`},
+		{KeyURL, "https://", `invalid value for "url": value must be an http or https URL`},
+		{KeyEmail, "test@example.com", ""},
+		{KeyEmail, "Test <test@example.com>", `invalid value for "email": value must be an email address`},
+		{KeyEmail, "test", `invalid value for "email": value must be an email address`},
+		{KeyGitHub, "ensdomains", ""},
+		{KeyGitHub, "coinbase-stablecoin", ""},
+		{KeyGitHub, "-ensdomains", `invalid value for "com.github": value must be a GitHub username`},
+		{KeyGitHub, "ens--domains", `invalid value for "com.github": value must be a GitHub username`},
+		{KeyGitHub, strings.Repeat("a", 40), `invalid value for "com.github": value must be a GitHub username`},
+		{KeyTwitter, "ensdomains", ""},
+		{KeyTwitter, "@ensdomains", `invalid value for "com.twitter": value must be a Twitter username without the @`},
+		{KeyDescription, "anything goes 🚀", ""},
+		// unset
+		{KeyURL, "", ""},
+		// unknown keys only get the generic checks
+		{"com.example.users", "anything", ""},
+		{"com.example.users", "\xff", `value of "com.example.users" is not valid UTF-8`},
+		{KeyDescription, "\xc3\x28", `value of "description" is not valid UTF-8`},
+		{KeyDescription, strings.Repeat("a", DefaultMaxValueSize+1), `value of "description" must be at most 8192 bytes long`},
+		// avatars may embed large images
+		{KeyAvatar, "data:image/png;base64," + strings.Repeat("AAAA", DefaultMaxValueSize), ""},
+		{KeyAvatar, "data:image/svg+xml," + strings.Repeat("%3Csvg%3E%3C%2Fsvg%3E", DefaultMaxValueSize/4), ""},
+	} {
+		err := Validate(tc.key, []byte(tc.value))
+		if tc.err == "" {
+			require.Nil(t, err, tc.value)
+		} else {
+			require.EqualError(t, err, tc.err)
+		}
+	}
+}
+
+func TestKnown(t *testing.T) {
+	for _, key := range []string{KeyAvatar, KeyDescription, KeyDisplay, KeyEmail, KeyKeywords, KeyMail, KeyNotice,
+		KeyLocation, KeyPhone, KeyURL, KeyGitHub, KeyPeepeth, KeyLinkedIn, KeyTwitter, KeyKeybase, KeyTelegram} {
+		require.True(t, DefaultRegistry.Known(key), key)
+	}
+	require.False(t, DefaultRegistry.Known("com.example.users"))
+	require.False(t, NewRegistry().Known(KeyAvatar))
+}
+
+func TestRegister(t *testing.T) {
+	r := NewDefaultRegistry()
+
+	require.Nil(t, r.Register("com.example.users", func(value string) error {
+		if !strings.HasPrefix(value, "user:") {
+			return errors.New("value must start with user:")
+		}
+		return nil
+	}))
+	require.True(t, r.Known("com.example.users"))
+	require.Nil(t, r.Validate("com.example.users", []byte("user:alice")))
+	require.EqualError(t, r.Validate("com.example.users", []byte("alice")), `invalid value for "com.example.users": value must start with user:`)
+
+	// built-in validators can be replaced
+	require.Nil(t, r.Register(KeyURL, nil))
+	require.Nil(t, r.Validate(KeyURL, []byte("ftp://example.com")))
+
+	for _, key := range []string{"users", "Com.Example", "com..example", "-com.example", ".com", "com.example."} {
+		require.EqualError(t, r.Register(key, nil), `key "`+key+`" must be in reverse-DNS notation`)
+	}
+
+	r.SetMaxValueSize(4)
+	require.EqualError(t, r.Validate("com.example.users", []byte("user:alice")), `value of "com.example.users" must be at most 4 bytes long`)
+	require.Nil(t, r.Validate(KeyAvatar, []byte("https://example.com/avatar.png")))
+
+	// the default registry is unaffected
+	require.False(t, DefaultRegistry.Known("com.example.users"))
+	require.NotNil(t, Validate(KeyURL, []byte("ftp://example.com")))
+}
@@ -0,0 +1,45 @@
+package textrecord
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// alphanumeric with single hyphens between characters
+	gitHubUsernameRegexp  = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+	twitterUsernameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+)
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("value must be an http or https URL")
+	}
+	return nil
+}
+
+func validateEmail(value string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return errors.New("value must be an email address")
+	}
+	return nil
+}
+
+func validateGitHubUsername(value string) error {
+	if len(value) > 39 || !gitHubUsernameRegexp.MatchString(value) {
+		return errors.New("value must be a GitHub username")
+	}
+	return nil
+}
+
+func validateTwitterUsername(value string) error {
+	if !twitterUsernameRegexp.MatchString(value) {
+		return errors.New("value must be a Twitter username without the @")
+	}
+	return nil
+}
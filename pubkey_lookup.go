@@ -0,0 +1,70 @@
+package coder
+
+import (
+	"bytes"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var _ Lookup = (*PubkeyLookup)(nil)
+
+type PubkeyLookup struct {
+	LookupRequest
+}
+
+func NewPubkeyLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*PubkeyLookup, error) {
+	nh, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
+	}
+
+	decoded, err := abi.IPubkeyResolver.Methods["pubkey"].Inputs.Unpack(lookupInputs)
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+	}
+
+	node, ok := decoded[0].([32]byte) // bytes32
+	if !ok {
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
+	}
+
+	if !bytes.Equal(node[:], nh[:]) {
+		return nil, ErrNodeMismatch
+	}
+
+	return &PubkeyLookup{newLookupRequest(name, node, abi.IPubkeyResolver.Methods["pubkey"], senderAddress, requestData)}, nil
+}
+
+func (l *PubkeyLookup) Kind() Kind {
+	return KindPubkey
+}
+
+// EncodeResult takes the 64-byte concatenation of the x and y coordinates of
+// the public key
+func (l *PubkeyLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if len(result) != 64 {
+		return nil, nil, errors.New("public key must be 64 bytes long")
+	}
+
+	var x, y [32]byte
+	copy(x[:], result[:32])
+	copy(y[:], result[32:])
+
+	return l.EncodePubkey(x, y, expires)
+}
+
+func (l *PubkeyLookup) EncodePubkey(x [32]byte, y [32]byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if encodedResult, err = abi.IPubkeyResolver.Methods["pubkey"].Outputs.Pack(
+		x, // bytes32
+		y, // bytes32
+	); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
+	}
+
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
+
+	return encodedResult, hash, nil
+}
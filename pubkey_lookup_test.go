@@ -0,0 +1,76 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func preparePubkeyLookup(t *testing.T) (senderAddress common.Address, requestData []byte, lookup *PubkeyLookup) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	pubkeyInputs, err := abi.IPubkeyResolver.Methods["pubkey"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	pubkeyCallData := make([]byte, len(pubkeyInputs)+4)
+	copy(pubkeyCallData, abi.IPubkeyResolver.Methods["pubkey"].ID)
+	copy(pubkeyCallData[4:], pubkeyInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, pubkeyCallData)
+	require.Nil(t, err)
+
+	requestData = make([]byte, len(resolveInputs)+4)
+	copy(requestData, abi.IResolverService.Methods["resolve"].ID)
+	copy(requestData[4:], resolveInputs)
+
+	lookup, err = NewPubkeyLookup(name, pubkeyInputs, *sender, requestData)
+	require.Nil(t, err)
+	require.Equal(t, name, lookup.Name())
+
+	return *sender, requestData, lookup
+}
+
+func TestPubkeyLookupEncodeResult(t *testing.T) {
+	sender, requestData, lookup := preparePubkeyLookup(t)
+
+	result, err := randomBytes(64)
+	require.Nil(t, err)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := lookup.EncodeResult(result, expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.IPubkeyResolver.Methods["pubkey"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+
+	x, y := decoded[0].([32]byte), decoded[1].([32]byte)
+	require.Equal(t, result[:32], x[:])
+	require.Equal(t, result[32:], y[:])
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestPubkeyLookupEncodeResultInvalidPubkey(t *testing.T) {
+	_, _, lookup := preparePubkeyLookup(t)
+
+	invalidPubkey, err := randomBytes(33)
+	require.Nil(t, err)
+
+	resultData, hash, err := lookup.EncodeResult(invalidPubkey, makeExpires())
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, "public key must be 64 bytes long")
+}
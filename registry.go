@@ -0,0 +1,244 @@
+package coder
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// DecodeFunc decodes the inputs of a resolver function call into a Lookup.
+// name is the decoded name from the resolve(bytes,bytes) call, lookupInputs are
+// the ABI-encoded inputs following the selector and requestData is the entire
+// resolve(bytes,bytes) calldata. Custom lookups can embed a LookupRequest to
+// implement most of the Lookup interface.
+type DecodeFunc func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error)
+
+// Registry maps resolver function selectors to decoders
+type Registry struct {
+	mu       sync.RWMutex
+	decoders map[[4]byte]registryEntry
+	strict   bool
+}
+
+type registryEntry struct {
+	decode DecodeFunc
+	// inputs of the resolver function, used to check for canonical encoding in
+	// strict mode. nil if registered without an ABI definition.
+	inputs ethabi.Arguments
+}
+
+// DefaultRegistry is used by DecodeRequest and has all the built-in lookups
+// registered
+var DefaultRegistry = NewDefaultRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[[4]byte]registryEntry)}
+}
+
+// NewDefaultRegistry returns a new registry with all the built-in lookups
+// registered
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	// addr(bytes32)
+	r.mustRegisterMethod(abi.IAddrResolver.Methods["addr"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewAddrLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// addr(bytes32,uint256)
+	r.mustRegisterMethod(abi.IMulticoinAddrResolver.Methods["addr"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewMulticoinAddrLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// text(bytes32,string)
+	r.mustRegisterMethod(abi.ITextResolver.Methods["text"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewTextLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// contenthash(bytes32)
+	r.mustRegisterMethod(abi.IContentHashResolver.Methods["contenthash"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewContenthashLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// name(bytes32)
+	r.mustRegisterMethod(abi.INameResolver.Methods["name"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewNameLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// pubkey(bytes32)
+	r.mustRegisterMethod(abi.IPubkeyResolver.Methods["pubkey"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewPubkeyLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// ABI(bytes32,uint256)
+	r.mustRegisterMethod(abi.IABIResolver.Methods["ABI"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewABILookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// interfaceImplementer(bytes32,bytes4)
+	r.mustRegisterMethod(abi.IInterfaceResolver.Methods["interfaceImplementer"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewInterfaceLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// dnsRecord(bytes32,bytes32,uint16)
+	r.mustRegisterMethod(abi.IDNSRecordResolver.Methods["dnsRecord"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return NewDNSRecordLookup(name, lookupInputs, senderAddress, requestData)
+	})
+	// multicall(bytes[]), the calls in the batch are decoded with this registry
+	r.mustRegisterMethod(abi.IMulticallable.Methods["multicall"], func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return newMulticallLookup(r, name, lookupInputs, senderAddress, requestData)
+	})
+
+	return r
+}
+
+// Register registers a decoder for the resolver function with the given
+// four-byte selector, replacing any decoder previously registered for it. In
+// strict mode, the decoder is responsible for rejecting non-canonical inputs;
+// use RegisterMethod to have the registry check them.
+func (r *Registry) Register(selector []byte, decode DecodeFunc) error {
+	return r.register(selector, nil, decode)
+}
+
+// RegisterMethod registers a decoder for the resolver function described by
+// method, replacing any decoder previously registered for its selector
+func (r *Registry) RegisterMethod(method ethabi.Method, decode DecodeFunc) error {
+	return r.register(method.ID, method.Inputs, decode)
+}
+
+func (r *Registry) register(selector []byte, inputs ethabi.Arguments, decode DecodeFunc) error {
+	if len(selector) != 4 {
+		return errors.New("selector must be 4 bytes long")
+	}
+	if decode == nil {
+		return errors.New("decoder must not be nil")
+	}
+
+	var key [4]byte
+	copy(key[:], selector)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[key] = registryEntry{decode, inputs}
+
+	return nil
+}
+
+func (r *Registry) mustRegisterMethod(method ethabi.Method, decode DecodeFunc) {
+	if err := r.RegisterMethod(method, decode); err != nil {
+		panic(err)
+	}
+}
+
+// SetStrict enables or disables strict mode. In strict mode, requests whose
+// resolve(bytes,bytes) calldata or resolver function inputs are not the
+// canonical ABI encoding of their decoded values are rejected with
+// ErrNonCanonicalABI, so that a signed response maps to exactly one request.
+func (r *Registry) SetStrict(strict bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strict = strict
+}
+
+func (r *Registry) isStrict() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.strict
+}
+
+// DecodeRequest decodes a resolve(bytes,bytes) call sent to the sender
+// contract, using the decoder registered for the selector of the inner call
+func (r *Registry) DecodeRequest(sender string, data string) (Lookup, error) {
+	senderBytes, err := decodeHex(sender)
+	if err != nil || len(senderBytes) != 20 {
+		return nil, ErrInvalidSender
+	}
+	senderAddress := common.BytesToAddress(senderBytes)
+
+	requestCallData, err := decodeHex(data)
+	if err != nil {
+		return nil, ErrInvalidHex
+	}
+
+	// check the first four-bytes to ensure that it's calling resolve(bytes,bytes)
+	if len(requestCallData) < 4 || !bytes.Equal(requestCallData[0:4], abi.SelectorResolve) {
+		return nil, ErrNotResolveCall
+	}
+
+	// decode resolve(bytes,bytes)
+	decoded, err := abi.IResolverService.Methods["resolve"].Inputs.Unpack(requestCallData[4:])
+	if err != nil {
+		return nil, wrapMalformedABIError(err, "failed to decode resolve calldata")
+	}
+
+	dnsNameBytes, ok := decoded[0].([]byte)
+	if !ok {
+		return nil, malformedABIError("failed to decode resolve calldata")
+	}
+
+	lookupCallData, ok := decoded[1].([]byte)
+	if !ok {
+		return nil, malformedABIError("failed to decode resolve calldata")
+	}
+
+	if r.isStrict() {
+		if err := checkCanonical(abi.IResolverService.Methods["resolve"].Inputs, decoded, requestCallData[4:]); err != nil {
+			return nil, err
+		}
+	}
+
+	// decode dns-encoded name, which must at least have the terminating zero-length label
+	name, err := dnsname.Decode(dnsNameBytes)
+	if err != nil {
+		return nil, wrapInvalidNameError(err, "failed to parse dns-encoded name in the resolve calldata")
+	}
+
+	return r.decodeLookup(name, lookupCallData, senderAddress, requestCallData)
+}
+
+func (r *Registry) decodeLookup(name string, lookupCallData []byte, senderAddress common.Address, requestCallData []byte) (Lookup, error) {
+	if len(lookupCallData) < 4 {
+		return nil, malformedABIError("call data is too short")
+	}
+
+	var lookupSelector [4]byte
+	copy(lookupSelector[:], lookupCallData[0:4])
+	lookupInputs := lookupCallData[4:]
+
+	r.mu.RLock()
+	entry, ok := r.decoders[lookupSelector]
+	strict := r.strict
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, &UnsupportedSelectorError{lookupSelector}
+	}
+
+	if strict && entry.inputs != nil {
+		decoded, err := entry.inputs.Unpack(lookupInputs)
+		if err != nil {
+			return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
+		}
+		if err := checkCanonical(entry.inputs, decoded, lookupInputs); err != nil {
+			return nil, err
+		}
+	}
+
+	lookup, err := entry.decode(name, lookupInputs, senderAddress, requestCallData)
+	if err != nil {
+		// avoid returning a typed nil pointer wrapped in a non-nil interface
+		return nil, err
+	}
+	return lookup, nil
+}
+
+// checkCanonical checks that data is exactly the ABI encoding of the values
+// decoded from it
+func checkCanonical(args ethabi.Arguments, decoded []interface{}, data []byte) error {
+	encoded, err := args.Pack(decoded...)
+	if err != nil {
+		return wrapMalformedABIError(err, "failed to re-encode decoded values")
+	}
+	if !bytes.Equal(encoded, data) {
+		return ErrNonCanonicalABI
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// customLookup is a lookup for the 0xcafebabe resolver function, which returns
+// its result as is
+type customLookup struct {
+	LookupRequest
+	inputs []byte
+}
+
+func newCustomLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+	node, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, err
+	}
+	selector := [4]byte{0xca, 0xfe, 0xba, 0xbe}
+	return &customLookup{NewLookupRequest(name, node, selector, senderAddress, requestData), lookupInputs}, nil
+}
+
+func (l *customLookup) EncodeResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	return result, l.ResultHash(result, expires), nil
+}
+
+func makeResolveCallData(t *testing.T, name string, lookupCallData []byte) []byte {
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, lookupCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	return resolveCallData
+}
+
+func TestRegistryCustomLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	selector := []byte{0xca, 0xfe, 0xba, 0xbe}
+	resolveCallData := makeResolveCallData(t, name, append(append([]byte{}, selector...), 0x01, 0x02))
+
+	registry := NewDefaultRegistry()
+	err = registry.Register(selector, newCustomLookup)
+	require.Nil(t, err)
+
+	req, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*customLookup)
+	require.True(t, ok, "expected the decoded lookup to be a customLookup")
+	require.Equal(t, name, lookup.Name())
+	require.Equal(t, []byte{0x01, 0x02}, lookup.inputs)
+
+	// accessors implemented by the embedded LookupRequest
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+	require.Equal(t, node, lookup.Node())
+	require.Equal(t, [4]byte{0xca, 0xfe, 0xba, 0xbe}, lookup.Selector())
+	require.Equal(t, *sender, lookup.Sender())
+	require.Equal(t, resolveCallData, lookup.RequestData())
+	require.Equal(t, KindUnknown, lookup.Kind())
+
+	// the default registry is not affected
+	req, err = DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, req)
+	require.EqualError(t, err, "unsupported lookup: 0xcafebabe")
+}
+
+func TestRegistryCustomLookupSignAndVerify(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	selector := []byte{0xca, 0xfe, 0xba, 0xbe}
+	resolveCallData := makeResolveCallData(t, randomName(), append(append([]byte{}, selector...), 0x01, 0x02))
+
+	registry := NewDefaultRegistry()
+	err = registry.Register(selector, newCustomLookup)
+	require.Nil(t, err)
+
+	lookup, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	signer := NewPrivateKeySigner(key)
+
+	responseData, err := SignAndEncode(signer, lookup, []byte("custom result"), 5*time.Minute)
+	require.Nil(t, err)
+
+	response, err := DecodeResponse(lookup, responseData)
+	require.Nil(t, err)
+	require.Equal(t, []byte("custom result"), response.Result)
+	require.Equal(t, signer.Address(), response.Signer)
+	require.Nil(t, Verify(response, []common.Address{signer.Address()}, time.Now()))
+}
+
+func TestRegistryCustomLookupInMulticall(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	selector := []byte{0xca, 0xfe, 0xba, 0xbe}
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack(
+		[][]byte{append(append([]byte{}, selector...), 0x01)},
+	)
+	require.Nil(t, err)
+	resolveCallData := makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...))
+
+	registry := NewDefaultRegistry()
+	err = registry.Register(selector, newCustomLookup)
+	require.Nil(t, err)
+
+	req, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	lookup, ok := req.(*MulticallLookup)
+	require.True(t, ok, "expected the decoded lookup to be a MulticallLookup")
+	require.Nil(t, lookup.Calls()[0].Err)
+	require.IsType(t, &customLookup{}, lookup.Calls()[0].Lookup)
+}
+
+func TestRegistryOverrideBuiltInLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+	resolveCallData := makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorAddr...), addrInputs...))
+
+	registry := NewDefaultRegistry()
+	err = registry.Register(abi.SelectorAddr, newCustomLookup)
+	require.Nil(t, err)
+
+	req, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+	require.IsType(t, &customLookup{}, req)
+}
+
+func TestRegistryEmpty(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+	resolveCallData := makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorAddr...), addrInputs...))
+
+	req, err := NewRegistry().DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, req)
+	require.EqualError(t, err, "unsupported lookup: "+hexutil.Encode(abi.SelectorAddr))
+}
+
+func TestRegistryDecoderErrorReturnsNilLookup(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	node, err := namehash.NameHash(randomName())
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	// the node does not match the name
+	resolveCallData := makeResolveCallData(t, randomName(), append(append([]byte{}, abi.SelectorAddr...), addrInputs...))
+
+	req, err := DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.True(t, req == nil, "expected an untyped nil lookup")
+	require.EqualError(t, err, "name hash does not match the lookup input")
+}
+
+func TestRegistryRegisterInvalid(t *testing.T) {
+	registry := NewRegistry()
+
+	err := registry.Register([]byte{0x01, 0x02, 0x03}, func(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (Lookup, error) {
+		return nil, nil
+	})
+	require.EqualError(t, err, "selector must be 4 bytes long")
+
+	err = registry.Register([]byte{0x01, 0x02, 0x03, 0x04}, nil)
+	require.EqualError(t, err, "decoder must not be nil")
+}
+
+func TestRegistryStrictMode(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	textRequest, err := EncodeTextRequest(name, "avatar")
+	require.Nil(t, err)
+
+	registry := NewDefaultRegistry()
+	registry.SetStrict(true)
+
+	req, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(textRequest))
+	require.Nil(t, err)
+	require.IsType(t, &TextLookup{}, req)
+
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	textInputs, err := abi.ITextResolver.Methods["text"].Inputs.Pack(node, "avatar")
+	require.Nil(t, err)
+
+	// non-zero bits in the padding after the key
+	dirtyPadding := append([]byte{}, textInputs...)
+	dirtyPadding[len(dirtyPadding)-1] = 0x01
+
+	// trailing bytes after the inputs
+	trailingInputs := append(append([]byte{}, textInputs...), 0x00)
+
+	for _, requestData := range [][]byte{
+		append(append([]byte{}, textRequest...), make([]byte, 32)...),
+		makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorText...), dirtyPadding...)),
+		makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorText...), trailingInputs...)),
+	} {
+		// accepted by default
+		req, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+		require.Nil(t, err)
+		require.IsType(t, &TextLookup{}, req)
+
+		req, err = registry.DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+		require.Nil(t, req)
+		require.True(t, errors.Is(err, ErrNonCanonicalABI), "expected %q to be %q", err, ErrNonCanonicalABI)
+	}
+}
+
+func TestRegistryStrictModeMulticall(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	multicallInputs, err := abi.IMulticallable.Methods["multicall"].Inputs.Pack([][]byte{
+		append(append([]byte{}, abi.SelectorAddr...), addrInputs...),
+		append(append(append([]byte{}, abi.SelectorAddr...), addrInputs...), 0x00),
+	})
+	require.Nil(t, err)
+	resolveCallData := makeResolveCallData(t, name, append(append([]byte{}, abi.SelectorMulticall...), multicallInputs...))
+
+	registry := NewDefaultRegistry()
+	registry.SetStrict(true)
+
+	req, err := registry.DecodeRequest(sender.Hex(), hexutil.Encode(resolveCallData))
+	require.Nil(t, err)
+
+	calls := req.(*MulticallLookup).Calls()
+	require.Nil(t, calls[0].Err)
+	require.True(t, errors.Is(calls[1].Err, ErrNonCanonicalABI))
+}
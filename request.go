@@ -0,0 +1,103 @@
+package coder
+
+import (
+	"math/big"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
+)
+
+// EncodeRequest returns the resolve(bytes,bytes) calldata for the given name
+// and resolver function calldata
+func EncodeRequest(name string, lookupCallData []byte) ([]byte, error) {
+	dn, err := dnsname.Encode(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dns-encode the name")
+	}
+
+	return encodeCall(abi.IResolverService.Methods["resolve"], dn, lookupCallData)
+}
+
+// EncodeAddrRequest returns the resolve(bytes,bytes) calldata for addr(bytes32)
+func EncodeAddrRequest(name string) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IAddrResolver.Methods["addr"])
+}
+
+// EncodeMulticoinAddrRequest returns the resolve(bytes,bytes) calldata for
+// addr(bytes32,uint256)
+func EncodeMulticoinAddrRequest(name string, coinType *big.Int) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IMulticoinAddrResolver.Methods["addr"], coinType)
+}
+
+// EncodeTextRequest returns the resolve(bytes,bytes) calldata for
+// text(bytes32,string)
+func EncodeTextRequest(name string, key string) ([]byte, error) {
+	return encodeNodeRequest(name, abi.ITextResolver.Methods["text"], key)
+}
+
+// EncodeContenthashRequest returns the resolve(bytes,bytes) calldata for
+// contenthash(bytes32)
+func EncodeContenthashRequest(name string) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IContentHashResolver.Methods["contenthash"])
+}
+
+// EncodeNameRequest returns the resolve(bytes,bytes) calldata for name(bytes32)
+func EncodeNameRequest(name string) ([]byte, error) {
+	return encodeNodeRequest(name, abi.INameResolver.Methods["name"])
+}
+
+// EncodePubkeyRequest returns the resolve(bytes,bytes) calldata for
+// pubkey(bytes32)
+func EncodePubkeyRequest(name string) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IPubkeyResolver.Methods["pubkey"])
+}
+
+// EncodeABIRequest returns the resolve(bytes,bytes) calldata for
+// ABI(bytes32,uint256)
+func EncodeABIRequest(name string, contentTypes *big.Int) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IABIResolver.Methods["ABI"], contentTypes)
+}
+
+// EncodeInterfaceRequest returns the resolve(bytes,bytes) calldata for
+// interfaceImplementer(bytes32,bytes4)
+func EncodeInterfaceRequest(name string, interfaceID [4]byte) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IInterfaceResolver.Methods["interfaceImplementer"], interfaceID)
+}
+
+// EncodeDNSRecordRequest returns the resolve(bytes,bytes) calldata for
+// dnsRecord(bytes32,bytes32,uint16)
+func EncodeDNSRecordRequest(name string, dnsName [32]byte, resource uint16) ([]byte, error) {
+	return encodeNodeRequest(name, abi.IDNSRecordResolver.Methods["dnsRecord"], dnsName, resource)
+}
+
+// encodeNodeRequest encodes a call to a resolver function that takes the node
+// as its first argument, wrapped in resolve(bytes,bytes)
+func encodeNodeRequest(name string, method ethabi.Method, args ...interface{}) ([]byte, error) {
+	node, err := namehash.NameHash(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get namehash")
+	}
+
+	lookupCallData, err := encodeCall(method, append([]interface{}{node}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeRequest(name, lookupCallData)
+}
+
+func encodeCall(method ethabi.Method, args ...interface{}) ([]byte, error) {
+	inputs, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ABI-encode %s", method.Sig)
+	}
+
+	callData := make([]byte, len(inputs)+4)
+	copy(callData, method.ID)
+	copy(callData[4:], inputs)
+
+	return callData, nil
+}
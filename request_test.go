@@ -0,0 +1,139 @@
+package coder
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeAddrRequest(t *testing.T) {
+	name := randomName()
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	// hand-assembled calldata
+	addrInputs, err := abi.IAddrResolver.Methods["addr"].Inputs.Pack(node)
+	require.Nil(t, err)
+
+	addrCallData := make([]byte, len(addrInputs)+4)
+	copy(addrCallData, abi.IAddrResolver.Methods["addr"].ID)
+	copy(addrCallData[4:], addrInputs)
+
+	dn, err := dnsname.Encode(name)
+	require.Nil(t, err)
+
+	resolveInputs, err := abi.IResolverService.Methods["resolve"].Inputs.Pack(dn, addrCallData)
+	require.Nil(t, err)
+
+	resolveCallData := make([]byte, len(resolveInputs)+4)
+	copy(resolveCallData, abi.IResolverService.Methods["resolve"].ID)
+	copy(resolveCallData[4:], resolveInputs)
+
+	requestData, err := EncodeAddrRequest(name)
+	require.Nil(t, err)
+	require.Equal(t, resolveCallData, requestData)
+}
+
+func TestEncodeRequestRoundTrip(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	name := randomName()
+	coinType := big.NewInt(0)
+	contentTypes := big.NewInt(1)
+	interfaceID := [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	dnsName := [32]byte{0x01}
+
+	node, err := namehash.NameHash(name)
+	require.Nil(t, err)
+
+	for _, tc := range []struct {
+		kind   Kind
+		method ethabi.Method
+		encode func() ([]byte, error)
+		check  func(lookup Lookup)
+	}{
+		{
+			KindAddr,
+			abi.IAddrResolver.Methods["addr"],
+			func() ([]byte, error) { return EncodeAddrRequest(name) },
+			func(lookup Lookup) { require.IsType(t, &AddrLookup{}, lookup) },
+		},
+		{
+			KindMulticoinAddr,
+			abi.IMulticoinAddrResolver.Methods["addr"],
+			func() ([]byte, error) { return EncodeMulticoinAddrRequest(name, coinType) },
+			func(lookup Lookup) { require.Equal(t, coinType, lookup.(*MulticoinAddrLookup).CoinType()) },
+		},
+		{
+			KindText,
+			abi.ITextResolver.Methods["text"],
+			func() ([]byte, error) { return EncodeTextRequest(name, "avatar") },
+			func(lookup Lookup) { require.Equal(t, "avatar", lookup.(*TextLookup).Key()) },
+		},
+		{
+			KindContenthash,
+			abi.IContentHashResolver.Methods["contenthash"],
+			func() ([]byte, error) { return EncodeContenthashRequest(name) },
+			func(lookup Lookup) { require.IsType(t, &ContenthashLookup{}, lookup) },
+		},
+		{
+			KindName,
+			abi.INameResolver.Methods["name"],
+			func() ([]byte, error) { return EncodeNameRequest(name) },
+			func(lookup Lookup) { require.IsType(t, &NameLookup{}, lookup) },
+		},
+		{
+			KindPubkey,
+			abi.IPubkeyResolver.Methods["pubkey"],
+			func() ([]byte, error) { return EncodePubkeyRequest(name) },
+			func(lookup Lookup) { require.IsType(t, &PubkeyLookup{}, lookup) },
+		},
+		{
+			KindABI,
+			abi.IABIResolver.Methods["ABI"],
+			func() ([]byte, error) { return EncodeABIRequest(name, contentTypes) },
+			func(lookup Lookup) { require.Equal(t, contentTypes, lookup.(*ABILookup).ContentTypes()) },
+		},
+		{
+			KindInterface,
+			abi.IInterfaceResolver.Methods["interfaceImplementer"],
+			func() ([]byte, error) { return EncodeInterfaceRequest(name, interfaceID) },
+			func(lookup Lookup) { require.Equal(t, interfaceID, lookup.(*InterfaceLookup).InterfaceID()) },
+		},
+		{
+			KindDNSRecord,
+			abi.IDNSRecordResolver.Methods["dnsRecord"],
+			func() ([]byte, error) { return EncodeDNSRecordRequest(name, dnsName, 1) },
+			func(lookup Lookup) { require.Equal(t, dnsName, lookup.(*DNSRecordLookup).DNSName()) },
+		},
+	} {
+		requestData, err := tc.encode()
+		require.Nil(t, err)
+
+		lookup, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+		require.Nil(t, err)
+		require.Equal(t, name, lookup.Name())
+		require.Equal(t, node, lookup.Node())
+		selector := lookup.Selector()
+		require.Equal(t, tc.method.ID, selector[:])
+		require.Equal(t, *sender, lookup.Sender())
+		require.Equal(t, requestData, lookup.RequestData())
+		require.Equal(t, tc.kind, lookup.Kind())
+		tc.check(lookup)
+	}
+}
+
+func TestEncodeRequestInvalidName(t *testing.T) {
+	// labels may not be longer than 255 bytes in a dns-encoded name
+	requestData, err := EncodeAddrRequest(strings.Repeat("a", 256) + ".eth")
+	require.Nil(t, requestData)
+	require.Contains(t, err.Error(), "failed to dns-encode the name")
+}
@@ -0,0 +1,105 @@
+package coder
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Response is a decoded response to a resolve(bytes,bytes) call
+type Response struct {
+	Result    []byte
+	Expires   uint64
+	Signature []byte
+	Hash      []byte
+	Signer    common.Address
+}
+
+// DecodeResponse decodes the response data returned by the gateway for the
+// lookup, recomputes the hash of the result and recovers the signer address
+func DecodeResponse(lookup Lookup, responseData []byte) (*Response, error) {
+	hasher, ok := lookup.(ResultHasher)
+	if !ok {
+		return nil, errors.New("lookup does not support response verification")
+	}
+
+	decoded, err := abi.IResolverService.Methods["resolve"].Outputs.Unpack(responseData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode response data")
+	}
+
+	result, ok := decoded[0].([]byte) // bytes
+	if !ok {
+		return nil, errors.New(`failed to decode "result" in response data`)
+	}
+
+	expires, ok := decoded[1].(uint64) // uint64
+	if !ok {
+		return nil, errors.New(`failed to decode "expires" in response data`)
+	}
+
+	sig, ok := decoded[2].([]byte) // bytes
+	if !ok {
+		return nil, errors.New(`failed to decode "sig" in response data`)
+	}
+
+	hash := hasher.ResultHash(result, expires)
+
+	signer, err := recoverSigner(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{result, expires, sig, hash, signer}, nil
+}
+
+// Verify checks that the response was signed by one of the signers and has not
+// expired at the given time, like SignatureVerifier.verify in the offchain
+// resolver contract
+// https://github.com/ensdomains/offchain-resolver/blob/main/packages/contracts/contracts/SignatureVerifier.sol
+func Verify(response *Response, signers []common.Address, now time.Time) error {
+	if now.Unix() < 0 || response.Expires < uint64(now.Unix()) {
+		return ErrSignatureExpired
+	}
+
+	for _, signer := range signers {
+		if signer == response.Signer {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func recoverSigner(hash []byte, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, errors.New("signature must be 65 bytes long")
+	}
+
+	v := sig[64]
+	if v != 27 && v != 28 {
+		return common.Address{}, errors.New(`invalid "v" value in the signature`)
+	}
+
+	// reject malleable signatures with s in the upper half of the curve order,
+	// as OpenZeppelin's ECDSA.recover does
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(v-27, r, s, true) {
+		return common.Address{}, errors.New("invalid signature values")
+	}
+
+	recoverable := make([]byte, 65)
+	copy(recoverable, sig)
+	recoverable[64] = v - 27
+
+	pubkey, err := crypto.SigToPub(hash, recoverable)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to recover signer")
+	}
+
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
@@ -0,0 +1,96 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	signer := NewPrivateKeySigner(key)
+
+	_, _, lookup := prepareTextLookup(t)
+
+	responseData, err := SignAndEncode(signer, lookup, []byte("hello"), 5*time.Minute)
+	require.Nil(t, err)
+
+	response, err := DecodeResponse(lookup, responseData)
+	require.Nil(t, err)
+
+	encodedResult, hash, err := lookup.EncodeResult([]byte("hello"), response.Expires)
+	require.Nil(t, err)
+
+	require.Equal(t, encodedResult, response.Result)
+	require.Equal(t, hash, response.Hash)
+	require.Equal(t, signer.Address(), response.Signer)
+
+	require.Nil(t, Verify(response, []common.Address{signer.Address()}, time.Now()))
+}
+
+func TestDecodeResponseDifferentLookup(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	signer := NewPrivateKeySigner(key)
+
+	_, _, lookup := prepareTextLookup(t)
+	_, _, otherLookup := prepareTextLookup(t)
+
+	responseData, err := SignAndEncode(signer, lookup, []byte("hello"), 5*time.Minute)
+	require.Nil(t, err)
+
+	// the signature does not cover the other request, so a different signer is recovered
+	response, err := DecodeResponse(otherLookup, responseData)
+	require.Nil(t, err)
+	require.NotEqual(t, signer.Address(), response.Signer)
+
+	require.EqualError(t, Verify(response, []common.Address{signer.Address()}, time.Now()), "invalid signature")
+}
+
+func TestDecodeResponseInvalid(t *testing.T) {
+	_, _, lookup := prepareTextLookup(t)
+
+	response, err := DecodeResponse(lookup, []byte{0x01, 0x02})
+	require.Nil(t, response)
+	require.Contains(t, err.Error(), "failed to decode response data")
+
+	mockSignature, err := randomBytes(65)
+	require.Nil(t, err)
+	mockSignature[64] = 29
+
+	responseData, err := EncodeResponse([]byte{}, makeExpires(), mockSignature)
+	require.Nil(t, err)
+
+	response, err = DecodeResponse(lookup, responseData)
+	require.Nil(t, response)
+	require.EqualError(t, err, `invalid "v" value in the signature`)
+
+	response, err = DecodeResponse(unverifiableLookup{&customLookup{}}, responseData)
+	require.Nil(t, response)
+	require.EqualError(t, err, "lookup does not support response verification")
+}
+
+// unverifiableLookup hides the ResultHash method of the wrapped lookup
+type unverifiableLookup struct {
+	Lookup
+}
+
+func TestVerify(t *testing.T) {
+	signer, err := randomAddress()
+	require.Nil(t, err)
+
+	otherSigner, err := randomAddress()
+	require.Nil(t, err)
+
+	now := time.Now()
+	response := &Response{Expires: uint64(now.Unix()), Signer: *signer}
+
+	require.Nil(t, Verify(response, []common.Address{*otherSigner, *signer}, now))
+	require.EqualError(t, Verify(response, []common.Address{*otherSigner}, now), "invalid signature")
+	require.EqualError(t, Verify(response, nil, now), "invalid signature")
+	require.EqualError(t, Verify(response, []common.Address{*signer}, now.Add(time.Second)), "signature expired")
+}
@@ -0,0 +1,89 @@
+package coder
+
+import (
+	"crypto/ecdsa"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Signer signs the hash returned by Lookup.EncodeResult
+type Signer interface {
+	// Sign returns a 65-byte [R || S || V] signature of the hash. V may be
+	// either 0/1 or 27/28.
+	Sign(hash []byte) ([]byte, error)
+	// Address returns the address of the signer, which must be one of the
+	// signers configured in the offchain resolver contract
+	Address() common.Address
+}
+
+var _ Signer = (*PrivateKeySigner)(nil)
+
+// PrivateKeySigner signs with an ECDSA private key held in memory
+type PrivateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{key, crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewPrivateKeySignerFromHex creates a signer from a hex-encoded private key
+func NewPrivateKeySignerFromHex(hexKey string) (*PrivateKeySigner, error) {
+	keyBytes, err := decodeHex(hexKey)
+	if err != nil {
+		return nil, errors.New("private key is not a valid hex string")
+	}
+
+	key, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid private key")
+	}
+
+	return NewPrivateKeySigner(key), nil
+}
+
+func (s *PrivateKeySigner) Sign(hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignAndEncode encodes the result for the lookup, signs it with the signer and
+// returns the response data for the resolve(bytes,bytes) call. The response
+// expires after ttl.
+func SignAndEncode(signer Signer, lookup Lookup, result []byte, ttl time.Duration) (responseData []byte, err error) {
+	expires := uint64(time.Now().Add(ttl).Unix())
+
+	encodedResult, hash, err := lookup.EncodeResult(result, expires)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.Sign(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign the result")
+	}
+
+	if len(signature) != 65 {
+		return nil, errors.New("signature must be 65 bytes long")
+	}
+
+	// the offchain resolver contract expects v to be 27 or 28
+	if signature[64] < 27 {
+		signature = append([]byte{}, signature...)
+		signature[64] += 27
+	}
+
+	return EncodeResponse(encodedResult, expires, signature)
+}
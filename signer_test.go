@@ -0,0 +1,102 @@
+package coder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// rawSigner returns signatures with v of 0 or 1, like most remote signers
+type rawSigner struct {
+	*PrivateKeySigner
+}
+
+func (s *rawSigner) Sign(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+func TestPrivateKeySigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	signer, err := NewPrivateKeySignerFromHex(hexutil.Encode(crypto.FromECDSA(key)))
+	require.Nil(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	hash := crypto.Keccak256([]byte("hello"))
+
+	sig, err := signer.Sign(hash)
+	require.Nil(t, err)
+	require.Len(t, sig, 65)
+	require.Contains(t, []byte{27, 28}, sig[64])
+
+	recoverable := append([]byte{}, sig...)
+	recoverable[64] -= 27
+	pubkey, err := crypto.SigToPub(hash, recoverable)
+	require.Nil(t, err)
+	require.Equal(t, signer.Address(), crypto.PubkeyToAddress(*pubkey))
+}
+
+func TestNewPrivateKeySignerFromHexInvalid(t *testing.T) {
+	signer, err := NewPrivateKeySignerFromHex("zebra")
+	require.Nil(t, signer)
+	require.EqualError(t, err, "private key is not a valid hex string")
+
+	signer, err = NewPrivateKeySignerFromHex("0x1234")
+	require.Nil(t, signer)
+	require.Contains(t, err.Error(), "invalid private key")
+}
+
+func TestSignAndEncode(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	for _, signer := range []Signer{NewPrivateKeySigner(key), &rawSigner{NewPrivateKeySigner(key)}} {
+		sender, requestData, lookup := prepareAddrLookup(t)
+
+		resultAddress, err := randomAddress()
+		require.Nil(t, err)
+
+		before := uint64(time.Now().Add(5 * time.Minute).Unix())
+		responseData, err := SignAndEncode(signer, lookup, resultAddress.Bytes(), 5*time.Minute)
+		require.Nil(t, err)
+		after := uint64(time.Now().Add(5 * time.Minute).Unix())
+
+		decoded, err := abi.IResolverService.Methods["resolve"].Outputs.Unpack(responseData)
+		require.Nil(t, err)
+
+		resultData := decoded[0].([]byte)
+		expires := decoded[1].(uint64)
+		sig := decoded[2].([]byte)
+
+		require.GreaterOrEqual(t, expires, before)
+		require.LessOrEqual(t, expires, after)
+
+		addrResult, err := abi.IAddrResolver.Methods["addr"].Outputs.Unpack(resultData)
+		require.Nil(t, err)
+		require.Equal(t, *resultAddress, addrResult[0])
+
+		require.Contains(t, []byte{27, 28}, sig[64])
+
+		recoverable := append([]byte{}, sig...)
+		recoverable[64] -= 27
+		pubkey, err := crypto.SigToPub(HashResult(sender, expires, requestData, resultData), recoverable)
+		require.Nil(t, err)
+		require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pubkey))
+	}
+}
+
+func TestSignAndEncodeInvalidResult(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	_, _, lookup := prepareAddrLookup(t)
+
+	responseData, err := SignAndEncode(NewPrivateKeySigner(key), lookup, []byte{0x01}, 5*time.Minute)
+	require.Nil(t, responseData)
+	require.EqualError(t, err, "address must be 20 bytes long")
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/gateway"
+)
+
+// NewBackend returns a gateway backend that answers the built-in lookups from
+// the store
+func NewBackend(s RecordStore) gateway.Backend {
+	return NewBackendWithAddrPolicy(s, gateway.AddrPolicy{})
+}
+
+// NewBackendWithAddrPolicy is like NewBackend, resolving addr(bytes32) and
+// addr(bytes32,uint256) lookups with the policy, e.g. to fall back to the
+// default EVM address for EVM chains
+func NewBackendWithAddrPolicy(s RecordStore, policy gateway.AddrPolicy) gateway.Backend {
+	addrResolver := gateway.AddrResolverFunc(func(ctx context.Context, query *gateway.AddrQuery) ([]byte, error) {
+		return s.Addr(ctx, query.Name, query.CoinType)
+	})
+
+	return gateway.NewAddrBackend(addrResolver, policy, gateway.BackendFunc(func(ctx context.Context, lookup coder.Lookup) ([]byte, error) {
+		switch l := lookup.(type) {
+		case *coder.TextLookup:
+			text, err := s.Text(ctx, l.Name(), l.Key())
+			if err != nil {
+				return nil, err
+			}
+			return []byte(text), nil
+
+		case *coder.ContenthashLookup:
+			return s.Contenthash(ctx, l.Name())
+
+		case *coder.NameLookup:
+			name, err := s.Name(ctx, l.Name())
+			if err != nil {
+				return nil, err
+			}
+			return []byte(name), nil
+
+		case *coder.PubkeyLookup:
+			return s.Pubkey(ctx, l.Name())
+
+		case *coder.ABILookup:
+			contentType, data, err := s.ABI(ctx, l.Name(), l.ContentTypes())
+			if err != nil {
+				return nil, err
+			}
+			return abi.IABIResolver.Methods["ABI"].Outputs.Pack(contentType, data)
+
+		case *coder.InterfaceLookup:
+			return s.Interface(ctx, l.Name(), l.InterfaceID())
+
+		case *coder.DNSRecordLookup:
+			return s.DNSRecord(ctx, l.Name(), l.DNSName(), l.Resource())
+		}
+
+		return nil, ErrNotFound
+	}))
+}
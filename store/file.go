@@ -0,0 +1,197 @@
+package store
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// fileRecord is a record in the format of the JSON database of the ENS
+// offchain-resolver gateway
+// https://github.com/ensdomains/offchain-resolver/tree/main/packages/gateway
+type fileRecord struct {
+	Addresses   map[string]string `json:"addresses" yaml:"addresses"`
+	Text        map[string]string `json:"text" yaml:"text"`
+	Contenthash string            `json:"contenthash" yaml:"contenthash"`
+	Name        string            `json:"name" yaml:"name"`
+	Pubkey      string            `json:"pubkey" yaml:"pubkey"`
+	ABIs        map[string]string `json:"abis" yaml:"abis"`
+	Interfaces  map[string]string `json:"interfaces" yaml:"interfaces"`
+	DNSRecords  []string          `json:"dnsRecords" yaml:"dnsRecords"`
+}
+
+// LoadFile loads a JSON or YAML file in the format of the ENS offchain-resolver
+// gateway's JSON database into a new MemoryStore. Files with a .yaml or .yml
+// extension are parsed as YAML.
+func LoadFile(path string) (*MemoryStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file")
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	}
+	return ParseJSON(data)
+}
+
+// ParseJSON parses records in the format of the ENS offchain-resolver
+// gateway's JSON database:
+//
+//	{
+//	  "test.eth": {
+//	    "addresses": {"60": "0x...", "0": "0x..."},
+//	    "text": {"email": "test@example.com"},
+//	    "contenthash": "0xe301..."
+//	  },
+//	  "*.test.eth": {
+//	    "text": {"url": "https://example.com"}
+//	  }
+//	}
+//
+// Records may also have the other profiles of the built-in lookups, which are
+// not part of that format: "pubkey" is the 64-byte x . y public key, "abis"
+// maps content types to ABI data, "interfaces" maps interface IDs to
+// implementer addresses and "dnsRecords" lists wire-format resource records,
+// all hex-encoded.
+//
+// Names may be wildcard patterns, see MemoryStore.
+func ParseJSON(data []byte) (*MemoryStore, error) {
+	var records map[string]fileRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JSON")
+	}
+	return newMemoryStoreFromFile(records)
+}
+
+// ParseYAML parses records in the same format as ParseJSON, written in YAML
+func ParseYAML(data []byte) (*MemoryStore, error) {
+	var records map[string]fileRecord
+	if err := yaml.Unmarshal(data, &records); err != nil {
+		return nil, errors.Wrap(err, "failed to parse YAML")
+	}
+	return newMemoryStoreFromFile(records)
+}
+
+func newMemoryStoreFromFile(records map[string]fileRecord) (*MemoryStore, error) {
+	s := NewMemoryStore()
+
+	// names that normalize to the same name would replace each other depending
+	// on the map iteration order
+	names := make(map[string]string, len(records))
+
+	for name, fr := range records {
+		normalized, err := normalizeName(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid name %q", name)
+		}
+		if other, ok := names[normalized]; ok {
+			if other > name {
+				other, name = name, other
+			}
+			return nil, errors.Errorf("names %q and %q normalize to the same name", other, name)
+		}
+		names[normalized] = name
+
+		record, err := fr.toRecord()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid record for %q", name)
+		}
+
+		if err := s.Set(name, record); err != nil {
+			return nil, errors.Wrapf(err, "invalid name %q", name)
+		}
+	}
+
+	return s, nil
+}
+
+func (fr *fileRecord) toRecord() (*Record, error) {
+	record := &Record{
+		Addresses: make(map[string][]byte, len(fr.Addresses)),
+		Text:      fr.Text,
+		Name:      fr.Name,
+	}
+
+	for coinType, addr := range fr.Addresses {
+		ct, ok := new(big.Int).SetString(coinType, 10)
+		if !ok || ct.Sign() < 0 {
+			return nil, errors.Errorf("invalid coin type %q", coinType)
+		}
+
+		b, err := hexutil.Decode(addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address for coin type %s", coinType)
+		}
+		record.Addresses[ct.String()] = b
+	}
+
+	if fr.Contenthash != "" {
+		b, err := hexutil.Decode(fr.Contenthash)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid contenthash")
+		}
+		record.Contenthash = b
+	}
+
+	if fr.Pubkey != "" {
+		b, err := hexutil.Decode(fr.Pubkey)
+		if err != nil || len(b) != 64 {
+			return nil, errors.New("public key must be 64 bytes long")
+		}
+		record.Pubkey = b
+	}
+
+	if len(fr.ABIs) > 0 {
+		record.ABIs = make(map[string][]byte, len(fr.ABIs))
+	}
+	for contentType, data := range fr.ABIs {
+		// content types are powers of two
+		ct, ok := new(big.Int).SetString(contentType, 10)
+		if !ok || ct.Sign() <= 0 || ct.TrailingZeroBits() != uint(ct.BitLen()-1) {
+			return nil, errors.Errorf("invalid content type %q", contentType)
+		}
+
+		b, err := hexutil.Decode(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ABI for content type %s", contentType)
+		}
+		record.ABIs[ct.String()] = b
+	}
+
+	if len(fr.Interfaces) > 0 {
+		record.Interfaces = make(map[string][]byte, len(fr.Interfaces))
+	}
+	for interfaceID, addr := range fr.Interfaces {
+		id, err := hexutil.Decode(interfaceID)
+		if err != nil || len(id) != 4 {
+			return nil, errors.Errorf("invalid interface ID %q", interfaceID)
+		}
+
+		b, err := hexutil.Decode(addr)
+		if err != nil || len(b) != 20 {
+			return nil, errors.Errorf("invalid implementer address for interface %s", interfaceID)
+		}
+		record.Interfaces[hexutil.Encode(id)] = b
+	}
+
+	for i, data := range fr.DNSRecords {
+		rr, err := hexutil.Decode(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid DNS record %d", i)
+		}
+		if _, _, err := parseDNSRecord(rr); err != nil {
+			return nil, errors.Wrapf(err, "invalid DNS record %d", i)
+		}
+		record.DNSRecords = append(record.DNSRecords, rr)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,279 @@
+package store
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ RecordStore = (*MemoryStore)(nil)
+	_ Matcher     = (*MemoryStore)(nil)
+)
+
+// MemoryStore is a RecordStore held in memory. Records can be set for wildcard
+// patterns such as "*.example.eth", which answer lookups for any subname of
+// example.eth that does not have a record of its own. When several patterns
+// match, the one closest to the name wins, and a record that matches never
+// falls back to a pattern for fields it does not have.
+//
+// Records are keyed by node, so that names with unknown labels like
+// "[9c22...b658].eth" find the record of the name with the same node, here
+// test.eth.
+type MemoryStore struct {
+	mu sync.RWMutex
+	// records maps the node of a name to its record
+	records map[[32]byte]memoryRecord
+	// wildcards maps the node of the parent of a pattern to its record
+	wildcards map[[32]byte]memoryRecord
+}
+
+type memoryRecord struct {
+	// name is the normalized name or pattern of the record
+	name   string
+	record *Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records:   make(map[[32]byte]memoryRecord),
+		wildcards: make(map[[32]byte]memoryRecord),
+	}
+}
+
+// Set sets the record for the name, replacing any existing record
+func (s *MemoryStore) Set(name string, record *Record) error {
+	normalized, node, wildcard, err := recordKey(name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wildcard {
+		s.wildcards[node] = memoryRecord{normalized, record}
+	} else {
+		s.records[node] = memoryRecord{normalized, record}
+	}
+
+	return nil
+}
+
+// Delete removes the record for the name
+func (s *MemoryStore) Delete(name string) error {
+	_, node, wildcard, err := recordKey(name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wildcard {
+		delete(s.wildcards, node)
+	} else {
+		delete(s.records, node)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Addr(ctx context.Context, name string, coinType *big.Int) ([]byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, ok := record.addr(coinType)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return addr, nil
+}
+
+func (s *MemoryStore) Text(ctx context.Context, name string, key string) (string, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := record.Text[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return text, nil
+}
+
+func (s *MemoryStore) Contenthash(ctx context.Context, name string) ([]byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Contenthash == nil {
+		return nil, ErrNotFound
+	}
+	return record.Contenthash, nil
+}
+
+func (s *MemoryStore) Name(ctx context.Context, name string) (string, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	if record.Name == "" {
+		return "", ErrNotFound
+	}
+	return record.Name, nil
+}
+
+func (s *MemoryStore) Pubkey(ctx context.Context, name string) ([]byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Pubkey == nil {
+		return nil, ErrNotFound
+	}
+	return record.Pubkey, nil
+}
+
+func (s *MemoryStore) ABI(ctx context.Context, name string, contentTypes *big.Int) (*big.Int, []byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contentType, data, ok := record.abi(contentTypes)
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return contentType, data, nil
+}
+
+func (s *MemoryStore) Interface(ctx context.Context, name string, interfaceID [4]byte) ([]byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, ok := record.implementer(interfaceID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return addr, nil
+}
+
+func (s *MemoryStore) DNSRecord(ctx context.Context, name string, dnsName [32]byte, resource uint16) ([]byte, error) {
+	record, _, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rrset := record.dnsRecords(dnsName, resource)
+	if rrset == nil {
+		return nil, ErrNotFound
+	}
+	return rrset, nil
+}
+
+func (s *MemoryStore) Match(ctx context.Context, name string) (string, error) {
+	_, pattern, err := s.get(name)
+	return pattern, err
+}
+
+// get returns the record for the name, looking for an exact match first and
+// then for the closest wildcard pattern, along with the matched name or pattern
+func (s *MemoryStore) get(name string) (record *Record, pattern string, err error) {
+	nodes, err := suffixNodes(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if r, ok := s.records[nodes[0]]; ok {
+		return r.record, r.name, nil
+	}
+
+	// a.b.eth matches *.b.eth, then *.eth, then *
+	for _, node := range nodes[1:] {
+		if r, ok := s.wildcards[node]; ok {
+			return r.record, r.name, nil
+		}
+	}
+
+	return nil, "", ErrNotFound
+}
+
+// suffixNodes returns the node of the name followed by the nodes of its
+// parents, ending with the root node. Unknown labels like "[4f5b...d7f0]" are
+// hashed as the label hash they hold.
+func suffixNodes(name string) ([][32]byte, error) {
+	if !namehash.HasEncodedLabels(name) {
+		normalized, err := normalizeName(name)
+		if err != nil {
+			return nil, err
+		}
+		name = normalized
+	}
+
+	labels := strings.Split(name, ".")
+	nodes := make([][32]byte, len(labels)+1)
+	for i := range labels {
+		node, err := namehash.NameHash(strings.Join(labels[i:], "."))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to normalize name")
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// recordKey returns the normalized name or pattern, and the node it is keyed
+// by: the node of the name, or the node of the parent of a pattern
+func recordKey(name string) (normalized string, node [32]byte, wildcard bool, err error) {
+	normalized, err = normalizeName(name)
+	if err != nil {
+		return "", node, false, err
+	}
+
+	parent := normalized
+	switch {
+	case normalized == "*":
+		parent, wildcard = "", true
+	case strings.HasPrefix(normalized, "*."):
+		parent, wildcard = normalized[2:], true
+	}
+
+	node, err = namehash.NameHash(parent)
+	if err != nil {
+		return "", node, false, errors.Wrap(err, "failed to normalize name")
+	}
+	return normalized, node, wildcard, nil
+}
+
+// normalizeName normalizes the name, leaving the leading "*" label of a
+// wildcard pattern as is
+func normalizeName(name string) (string, error) {
+	if name == "*" {
+		return name, nil
+	}
+
+	prefix := ""
+	if strings.HasPrefix(name, "*.") {
+		prefix, name = "*.", name[2:]
+	}
+
+	normalized, err := namehash.Normalize(name)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to normalize name")
+	}
+	return prefix + normalized, nil
+}
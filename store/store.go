@@ -0,0 +1,131 @@
+// Package store provides record backends for the gateway, keyed by normalized
+// ENS name.
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/gateway"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when there is no record for the name, or the record
+// does not have the requested field
+var ErrNotFound = gateway.ErrNotFound
+
+// RecordStore looks up resolver records by name. Names are normalized before
+// they are looked up.
+type RecordStore interface {
+	// Addr returns the address for the coin type in its binary form, as
+	// returned by addr(bytes32,uint256)
+	Addr(ctx context.Context, name string, coinType *big.Int) ([]byte, error)
+	Text(ctx context.Context, name string, key string) (string, error)
+	// Contenthash returns the raw EIP-1577 contenthash
+	Contenthash(ctx context.Context, name string) ([]byte, error)
+	// Name returns the primary name for a reverse name
+	Name(ctx context.Context, name string) (string, error)
+	// Pubkey returns the x and y coordinates of the public key, 64 bytes
+	Pubkey(ctx context.Context, name string) ([]byte, error)
+	// ABI returns the ABI with the lowest content type in the contentTypes
+	// bitmask, as returned by ABI(bytes32,uint256)
+	ABI(ctx context.Context, name string, contentTypes *big.Int) (contentType *big.Int, data []byte, err error)
+	// Interface returns the address of the contract implementing the interface
+	Interface(ctx context.Context, name string, interfaceID [4]byte) ([]byte, error)
+	// DNSRecord returns the wire-format resource records of the given type for
+	// the DNS name whose wire format hashes to dnsName, as returned by
+	// dnsRecord(bytes32,bytes32,uint16)
+	DNSRecord(ctx context.Context, name string, dnsName [32]byte, resource uint16) ([]byte, error)
+}
+
+// Matcher is implemented by stores that support wildcard records, to report
+// which record answers lookups for a name
+type Matcher interface {
+	// Match returns the name of the record that answers lookups for name,
+	// which is either the normalized name itself or a wildcard pattern such as
+	// "*.example.eth"
+	Match(ctx context.Context, name string) (pattern string, err error)
+}
+
+// Record holds the records of a single name
+type Record struct {
+	// Addresses maps coin types to addresses in their binary form
+	Addresses map[string][]byte
+	Text      map[string]string
+	// Contenthash is the raw EIP-1577 contenthash
+	Contenthash []byte
+	// Name is the primary name, for reverse records
+	Name string
+	// Pubkey is the x and y coordinates of the public key, 64 bytes
+	Pubkey []byte
+	// ABIs maps content types to ABI data
+	ABIs map[string][]byte
+	// Interfaces maps interface IDs, like "0x01ffc9a7", to the addresses of the
+	// contracts implementing them
+	Interfaces map[string][]byte
+	// DNSRecords holds wire-format resource records, for the name and the
+	// names in its zone
+	DNSRecords [][]byte
+}
+
+func (r *Record) addr(coinType *big.Int) ([]byte, bool) {
+	addr, ok := r.Addresses[coinType.String()]
+	return addr, ok
+}
+
+// abi returns the ABI with the lowest content type set in contentTypes
+func (r *Record) abi(contentTypes *big.Int) (*big.Int, []byte, bool) {
+	for i := 0; i < contentTypes.BitLen(); i++ {
+		if contentTypes.Bit(i) == 0 {
+			continue
+		}
+		contentType := new(big.Int).Lsh(big.NewInt(1), uint(i))
+		if data, ok := r.ABIs[contentType.String()]; ok {
+			return contentType, data, true
+		}
+	}
+	return nil, nil, false
+}
+
+func (r *Record) implementer(interfaceID [4]byte) ([]byte, bool) {
+	addr, ok := r.Interfaces[hexutil.Encode(interfaceID[:])]
+	return addr, ok
+}
+
+// dnsRecords returns the records of the given type for the DNS name whose wire
+// format hashes to dnsName
+func (r *Record) dnsRecords(dnsName [32]byte, resource uint16) []byte {
+	var rrset []byte
+	for _, rr := range r.DNSRecords {
+		nameEnd, rrType, err := parseDNSRecord(rr)
+		if err != nil || rrType != resource || crypto.Keccak256Hash(rr[:nameEnd]) != dnsName {
+			continue
+		}
+		rrset = append(rrset, rr...)
+	}
+	return rrset
+}
+
+// parseDNSRecord parses a single wire-format resource record and returns the
+// end of its name and its type
+func parseDNSRecord(rr []byte) (nameEnd int, rrType uint16, err error) {
+	if nameEnd, err = dnsname.ReadName(rr, 0); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid name")
+	}
+
+	// type (2) . class (2) . ttl (4) . rdlength (2)
+	if len(rr)-nameEnd < 10 {
+		return 0, 0, errors.New("record is truncated")
+	}
+
+	rdLength := int(binary.BigEndian.Uint16(rr[nameEnd+8:]))
+	if len(rr) != nameEnd+10+rdLength {
+		return 0, 0, errors.New("record data does not match its length")
+	}
+
+	return nameEnd, binary.BigEndian.Uint16(rr[nameEnd:]), nil
+}
@@ -0,0 +1,431 @@
+package store
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coder "github.com/CoinbaseStablecoin/ens-offchain-lookup-coder"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/gateway"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testJSON = `{
+	"test.eth": {
+		"addresses": {
+			"60": "0x314159265dd8dbb310642f98f50c066173c1259b",
+			"0": "0xa91462e910b6cd5fd8cb48e34e4e2ad7aec9ddbdd4ec87"
+		},
+		"text": {
+			"email": "test@example.com"
+		},
+		"contenthash": "0xe301017012204edd2984eeaf3ddf50bac238ec95c5713fb40b5e428b508fdbe55d3b9f155ffe"
+	},
+	"Alice.Test.eth": {
+		"text": {
+			"url": "https://example.com"
+		}
+	}
+}`
+
+const testYAML = `
+test.eth:
+  addresses:
+    "60": "0x314159265dd8dbb310642f98f50c066173c1259b"
+  text:
+    email: test@example.com
+`
+
+func TestParseJSON(t *testing.T) {
+	s, err := ParseJSON([]byte(testJSON))
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	addr, err := s.Addr(ctx, "test.eth", big.NewInt(60))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x314159265dd8dbb310642f98f50c066173c1259b").Bytes(), addr)
+
+	addr, err = s.Addr(ctx, "test.eth", big.NewInt(0))
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0xa91462e910b6cd5fd8cb48e34e4e2ad7aec9ddbdd4ec87"), addr)
+
+	text, err := s.Text(ctx, "test.eth", "email")
+	require.Nil(t, err)
+	require.Equal(t, "test@example.com", text)
+
+	contenthash, err := s.Contenthash(ctx, "test.eth")
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0xe301017012204edd2984eeaf3ddf50bac238ec95c5713fb40b5e428b508fdbe55d3b9f155ffe"), contenthash)
+
+	// names are normalized
+	text, err = s.Text(ctx, "alice.test.eth", "url")
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com", text)
+
+	text, err = s.Text(ctx, "ALICE.test.eth", "url")
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com", text)
+
+	_, err = s.Addr(ctx, "test.eth", big.NewInt(2))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.Text(ctx, "unknown.eth", "email")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.Contenthash(ctx, "alice.test.eth")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.Name(ctx, "test.eth")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`[]`,
+		`{"test.eth": {"addresses": {"sixty": "0x00"}}}`,
+		`{"test.eth": {"addresses": {"60": "zebra"}}}`,
+		`{"test.eth": {"contenthash": "e301"}}`,
+		`{"test.eth": {"pubkey": "0x1122"}}`,
+		`{"test.eth": {"abis": {"3": "0x00"}}}`,
+		`{"test.eth": {"abis": {"0": "0x00"}}}`,
+		`{"test.eth": {"abis": {"1": "zebra"}}}`,
+		`{"test.eth": {"interfaces": {"0x01ffc9": "0x000000000000000000000000000000000000c0de"}}}`,
+		`{"test.eth": {"interfaces": {"0x01ffc9a7": "0xc0de"}}}`,
+		`{"test.eth": {"dnsRecords": ["0x0474657374036574680000010001"]}}`,
+		`{"test.eth": {"dnsRecords": ["0x047465737403657468000001000100000e1000047f00000100"]}}`,
+	} {
+		s, err := ParseJSON([]byte(data))
+		require.Nil(t, s, data)
+		require.NotNil(t, err, data)
+	}
+}
+
+func TestParseJSONDuplicateNames(t *testing.T) {
+	for _, data := range []string{
+		`{"Test.eth": {"text": {"url": "a"}}, "test.eth": {"text": {"url": "b"}}}`,
+		`{"test.eth": {"text": {"url": "a"}}, "Test.eth": {"text": {"url": "b"}}}`,
+	} {
+		s, err := ParseJSON([]byte(data))
+		require.Nil(t, s, data)
+		require.EqualError(t, err, `names "Test.eth" and "test.eth" normalize to the same name`, data)
+	}
+
+	s, err := ParseYAML([]byte("\"*.Test.eth\": {}\n\"*.test.eth\": {}\n"))
+	require.Nil(t, s)
+	require.EqualError(t, err, `names "*.Test.eth" and "*.test.eth" normalize to the same name`)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "db.json")
+	require.Nil(t, os.WriteFile(jsonPath, []byte(testJSON), 0600))
+
+	yamlPath := filepath.Join(dir, "db.yaml")
+	require.Nil(t, os.WriteFile(yamlPath, []byte(testYAML), 0600))
+
+	for _, path := range []string{jsonPath, yamlPath} {
+		s, err := LoadFile(path)
+		require.Nil(t, err, path)
+
+		text, err := s.Text(context.Background(), "test.eth", "email")
+		require.Nil(t, err, path)
+		require.Equal(t, "test@example.com", text)
+	}
+
+	_, err := LoadFile(filepath.Join(dir, "missing.json"))
+	require.Contains(t, err.Error(), "failed to read file")
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	require.Nil(t, s.Set("1234.addr.reverse", &Record{Name: "test.eth"}))
+
+	name, err := s.Name(ctx, "1234.addr.reverse")
+	require.Nil(t, err)
+	require.Equal(t, "test.eth", name)
+
+	require.Nil(t, s.Delete("1234.addr.reverse"))
+
+	_, err = s.Name(ctx, "1234.addr.reverse")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestBackend(t *testing.T) {
+	s, err := ParseJSON([]byte(testJSON))
+	require.Nil(t, err)
+
+	backend := NewBackend(s)
+	ctx := context.Background()
+	sender := common.HexToAddress("0x000000000000000000000000000000000000c0de").Hex()
+
+	decode := func(requestData []byte, err error) coder.Lookup {
+		require.Nil(t, err)
+		lookup, err := coder.DecodeRequest(sender, hexutil.Encode(requestData))
+		require.Nil(t, err)
+		return lookup
+	}
+
+	result, err := backend.Resolve(ctx, decode(coder.EncodeAddrRequest("test.eth")))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x314159265dd8dbb310642f98f50c066173c1259b").Bytes(), result)
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest("test.eth", big.NewInt(0))))
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0xa91462e910b6cd5fd8cb48e34e4e2ad7aec9ddbdd4ec87"), result)
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeTextRequest("test.eth", "email")))
+	require.Nil(t, err)
+	require.Equal(t, []byte("test@example.com"), result)
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeContenthashRequest("test.eth")))
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0xe301017012204edd2984eeaf3ddf50bac238ec95c5713fb40b5e428b508fdbe55d3b9f155ffe"), result)
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodePubkeyRequest("test.eth")))
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestBackendProfiles(t *testing.T) {
+	const (
+		a1  = "0x047465737403657468000001000100000e1000047f000001"         // test.eth. A 127.0.0.1
+		a2  = "0x047465737403657468000001000100000e1000047f000002"         // test.eth. A 127.0.0.2
+		www = "0x03777777047465737403657468000001000100000e1000047f000003" // www.test.eth. A 127.0.0.3
+	)
+
+	s, err := ParseJSON([]byte(`{
+		"test.eth": {
+			"pubkey": "0x` + strings.Repeat("11", 32) + strings.Repeat("22", 32) + `",
+			"abis": {"1": "0x5b5d", "4": "0xc0"},
+			"interfaces": {"0x01FFC9A7": "0x000000000000000000000000000000000000c0de"},
+			"dnsRecords": ["` + a1 + `", "` + www + `", "` + a2 + `"]
+		}
+	}`))
+	require.Nil(t, err)
+
+	backend := NewBackend(s)
+	ctx := context.Background()
+	sender := common.HexToAddress("0x000000000000000000000000000000000000c0de").Hex()
+
+	decode := func(requestData []byte, err error) coder.Lookup {
+		require.Nil(t, err)
+		lookup, err := coder.DecodeRequest(sender, hexutil.Encode(requestData))
+		require.Nil(t, err)
+		return lookup
+	}
+
+	result, err := backend.Resolve(ctx, decode(coder.EncodePubkeyRequest("test.eth")))
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode("0x"+strings.Repeat("11", 32)+strings.Repeat("22", 32)), result)
+
+	// the lowest content type in the bitmask that has an ABI is returned
+	for _, tc := range []struct {
+		contentTypes int64
+		contentType  int64
+		data         []byte
+	}{
+		{1, 1, []byte("[]")},
+		{5, 1, []byte("[]")},
+		{6, 4, []byte{0xc0}},
+	} {
+		result, err = backend.Resolve(ctx, decode(coder.EncodeABIRequest("test.eth", big.NewInt(tc.contentTypes))))
+		require.Nil(t, err)
+
+		decoded, err := abi.IABIResolver.Methods["ABI"].Outputs.Unpack(result)
+		require.Nil(t, err)
+		require.Equal(t, big.NewInt(tc.contentType), decoded[0])
+		require.Equal(t, tc.data, decoded[1])
+	}
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodeABIRequest("test.eth", big.NewInt(2))))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeInterfaceRequest("test.eth", [4]byte{0x01, 0xff, 0xc9, 0xa7})))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x000000000000000000000000000000000000c0de").Bytes(), result)
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodeInterfaceRequest("test.eth", [4]byte{0xde, 0xad, 0xbe, 0xef})))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	// records of the DNS name and type, in order
+	dnsName := crypto.Keccak256Hash([]byte("\x04test\x03eth\x00"))
+	result, err = backend.Resolve(ctx, decode(coder.EncodeDNSRecordRequest("test.eth", dnsName, 1)))
+	require.Nil(t, err)
+	require.Equal(t, append(hexutil.MustDecode(a1), hexutil.MustDecode(a2)...), result)
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeDNSRecordRequest("test.eth", crypto.Keccak256Hash([]byte("\x03www\x04test\x03eth\x00")), 1)))
+	require.Nil(t, err)
+	require.Equal(t, hexutil.MustDecode(www), result)
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodeDNSRecordRequest("test.eth", dnsName, 16)))
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestBackendWithAddrPolicy(t *testing.T) {
+	s, err := ParseJSON([]byte(`{
+		"test.eth": {
+			"addresses": {
+				"60": "0x314159265dd8dbb310642f98f50c066173c1259b",
+				"2147483648": "0x000000000000000000000000000000000000defa"
+			}
+		}
+	}`))
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	sender := common.HexToAddress("0x000000000000000000000000000000000000c0de").Hex()
+
+	decode := func(requestData []byte, err error) coder.Lookup {
+		require.Nil(t, err)
+		lookup, err := coder.DecodeRequest(sender, hexutil.Encode(requestData))
+		require.Nil(t, err)
+		return lookup
+	}
+
+	_, err = NewBackend(s).Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest("test.eth", big.NewInt(0x8000000a))))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	backend := NewBackendWithAddrPolicy(s, gateway.AddrPolicy{Fallbacks: []gateway.AddrFallback{gateway.FallbackToDefaultEVM}})
+
+	result, err := backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest("test.eth", big.NewInt(0x8000000a))))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x000000000000000000000000000000000000defa").Bytes(), result)
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeAddrRequest("test.eth")))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x314159265dd8dbb310642f98f50c066173c1259b").Bytes(), result)
+
+	// chains fall back to the address of addr(bytes32)
+	backend = NewBackendWithAddrPolicy(s, gateway.AddrPolicy{Fallbacks: []gateway.AddrFallback{gateway.FallbackToETH}})
+
+	result, err = backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest("test.eth", big.NewInt(0x8000000a))))
+	require.Nil(t, err)
+	require.Equal(t, common.HexToAddress("0x314159265dd8dbb310642f98f50c066173c1259b").Bytes(), result)
+
+	_, err = backend.Resolve(ctx, decode(coder.EncodeMulticoinAddrRequest("test.eth", big.NewInt(0))))
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestWildcard(t *testing.T) {
+	s, err := ParseJSON([]byte(`{
+		"*.cb.id": {"text": {"url": "https://cb.id"}},
+		"*.team.cb.id": {"text": {"url": "https://team.cb.id"}},
+		"bob.cb.id": {"text": {"email": "bob@example.com"}},
+		"*": {"text": {"url": "https://example.com"}}
+	}`))
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name    string
+		pattern string
+		url     string
+	}{
+		{"alice.cb.id", "*.cb.id", "https://cb.id"},
+		{"ALICE.cb.id", "*.cb.id", "https://cb.id"},
+		{"a.b.cb.id", "*.cb.id", "https://cb.id"},
+		{"alice.team.cb.id", "*.team.cb.id", "https://team.cb.id"},
+		{"team.cb.id", "*.cb.id", "https://cb.id"},
+		{"cb.id", "*", "https://example.com"},
+		{"test.eth", "*", "https://example.com"},
+	} {
+		pattern, err := s.Match(ctx, tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.pattern, pattern, tc.name)
+
+		url, err := s.Text(ctx, tc.name, "url")
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.url, url, tc.name)
+	}
+
+	// exact match takes precedence, and does not fall back to the pattern
+	pattern, err := s.Match(ctx, "bob.cb.id")
+	require.Nil(t, err)
+	require.Equal(t, "bob.cb.id", pattern)
+
+	email, err := s.Text(ctx, "bob.cb.id", "email")
+	require.Nil(t, err)
+	require.Equal(t, "bob@example.com", email)
+
+	_, err = s.Text(ctx, "bob.cb.id", "url")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	require.Nil(t, s.Delete("*"))
+
+	_, err = s.Match(ctx, "test.eth")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestEncodedLabels(t *testing.T) {
+	const unknown = "[41b1a0649752af1b28b3dc29a1556eee781e4a4c3a1f7f53f90fa834de098c4d]"
+	// label hash of "test"
+	const test = "[9c22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658]"
+
+	s, err := ParseJSON([]byte(`{
+		"test.eth": {"text": {"url": "https://test.example.com"}},
+		"*.test.eth": {"text": {"url": "https://wildcard.example.com"}},
+		"*": {"text": {"url": "https://root.example.com"}}
+	}`))
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name    string
+		pattern string
+	}{
+		// wildcards stand in for unknown labels
+		{unknown + ".test.eth", "*.test.eth"},
+		{"a." + unknown + ".test.eth", "*.test.eth"},
+		{unknown + ".Test.eth", "*.test.eth"},
+		// but not for known labels after them
+		{unknown + ".eth", "*"},
+		{"test." + unknown, "*"},
+		{unknown, "*"},
+		// encoded labels match the records of the labels they hash
+		{test + ".eth", "test.eth"},
+		{"a." + test + ".eth", "*.test.eth"},
+		{unknown + "." + test + ".eth", "*.test.eth"},
+	} {
+		pattern, err := s.Match(ctx, tc.name)
+		require.Nil(t, err, tc.name)
+		require.Equal(t, tc.pattern, pattern, tc.name)
+	}
+
+	url, err := s.Text(ctx, unknown+".test.eth", "url")
+	require.Nil(t, err)
+	require.Equal(t, "https://wildcard.example.com", url)
+
+	// without a matching wildcard
+	s, err = ParseJSON([]byte(`{"test.eth": {"text": {"url": "https://example.com"}}}`))
+	require.Nil(t, err)
+
+	_, err = s.Text(ctx, unknown+".test.eth", "url")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	// the record of the name takes precedence over wildcards of its parents
+	s, err = ParseJSON([]byte(`{
+		"test.eth": {"text": {"url": "https://test.example.com"}},
+		"*.eth": {"text": {"url": "https://wildcard.example.com"}}
+	}`))
+	require.Nil(t, err)
+
+	pattern, err := s.Match(ctx, test+".eth")
+	require.Nil(t, err)
+	require.Equal(t, "test.eth", pattern)
+
+	url, err = s.Text(ctx, test+".eth", "url")
+	require.Nil(t, err)
+	require.Equal(t, "https://test.example.com", url)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/textrecord"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 )
@@ -12,42 +13,40 @@ import (
 var _ Lookup = (*TextLookup)(nil)
 
 type TextLookup struct {
-	name          string
-	senderAddress common.Address
-	requestData   []byte
-	key           string
+	LookupRequest
+	key string
 }
 
 func NewTextLookup(name string, lookupInputs []byte, senderAddress common.Address, requestData []byte) (*TextLookup, error) {
 	nh, err := namehash.NameHash(name)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get namehash")
+		return nil, wrapInvalidNameError(err, "failed to get namehash")
 	}
 
 	decoded, err := abi.ITextResolver.Methods["text"].Inputs.Unpack(lookupInputs)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode lookup inputs")
+		return nil, wrapMalformedABIError(err, "failed to decode lookup inputs")
 	}
 
 	node, ok := decoded[0].([32]byte) // bytes32
 	if !ok {
-		return nil, errors.New(`failed to decode "node" in lookup inputs`)
+		return nil, malformedABIError(`failed to decode "node" in lookup inputs`)
 	}
 
 	key, ok := decoded[1].(string) // string
 	if !ok {
-		return nil, errors.New(`failed to decode "key" in lookup inputs`)
+		return nil, malformedABIError(`failed to decode "key" in lookup inputs`)
 	}
 
 	if !bytes.Equal(node[:], nh[:]) {
-		return nil, errors.New("name hash does not match the lookup input")
+		return nil, ErrNodeMismatch
 	}
 
-	return &TextLookup{name, senderAddress, requestData, key}, nil
+	return &TextLookup{newLookupRequest(name, node, abi.ITextResolver.Methods["text"], senderAddress, requestData), key}, nil
 }
 
-func (l *TextLookup) Name() string {
-	return l.name
+func (l *TextLookup) Kind() Kind {
+	return KindText
 }
 
 func (l *TextLookup) Key() string {
@@ -61,7 +60,23 @@ func (l *TextLookup) EncodeResult(result []byte, expires uint64) (encodedResult
 		return nil, nil, errors.Wrap(err, "failed to ABI-encode the result")
 	}
 
-	hash = hashResult(l.senderAddress, expires, l.requestData, encodedResult)
+	hash = HashResult(l.senderAddress, expires, l.requestData, encodedResult)
 
 	return encodedResult, hash, nil
 }
+
+// ValidateResult checks the value of the text record against the key of the
+// lookup with textrecord.DefaultRegistry: the value must be valid UTF-8, not
+// too large and in the format of well-known keys like "url" or "email"
+func (l *TextLookup) ValidateResult(result []byte) error {
+	return textrecord.Validate(l.key, result)
+}
+
+// EncodeValidatedResult is like EncodeResult, but rejects results that fail
+// ValidateResult
+func (l *TextLookup) EncodeValidatedResult(result []byte, expires uint64) (encodedResult []byte, hash []byte, err error) {
+	if err := l.ValidateResult(result); err != nil {
+		return nil, nil, err
+	}
+	return l.EncodeResult(result, expires)
+}
@@ -7,9 +7,10 @@ import (
 	"time"
 
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/abi"
+	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/internal/dnsname"
 	"github.com/CoinbaseStablecoin/ens-offchain-lookup-coder/pkg/namehash"
 	"github.com/ethereum/go-ethereum/common"
-	dnsname "github.com/petejkim/ens-dnsname"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,5 +63,38 @@ func TestTextLookupEncodeResult(t *testing.T) {
 	require.Nil(t, err)
 
 	require.Equal(t, result, decoded[0])
-	require.Equal(t, hashResult(sender, expires, requestData, resultData), hash)
+	require.Equal(t, HashResult(sender, expires, requestData, resultData), hash)
+}
+
+func TestTextLookupEncodeValidatedResult(t *testing.T) {
+	sender, err := randomAddress()
+	require.Nil(t, err)
+
+	requestData, err := EncodeTextRequest(randomName(), "url")
+	require.Nil(t, err)
+
+	lookup, err := DecodeRequest(sender.Hex(), hexutil.Encode(requestData))
+	require.Nil(t, err)
+	textLookup := lookup.(*TextLookup)
+
+	expires := uint64(time.Now().Unix() + 300)
+
+	resultData, hash, err := textLookup.EncodeValidatedResult([]byte("https://example.com"), expires)
+	require.Nil(t, err)
+
+	decoded, err := abi.ITextResolver.Methods["text"].Outputs.Unpack(resultData)
+	require.Nil(t, err)
+	require.Equal(t, "https://example.com", decoded[0])
+	require.Equal(t, HashResult(*sender, expires, requestData, resultData), hash)
+
+	resultData, hash, err = textLookup.EncodeValidatedResult([]byte("example.com"), expires)
+	require.Nil(t, resultData)
+	require.Nil(t, hash)
+	require.EqualError(t, err, `invalid value for "url": value must be an http or https URL`)
+
+	require.EqualError(t, textLookup.ValidateResult([]byte{0xff}), `value of "url" is not valid UTF-8`)
+
+	// EncodeResult does not validate
+	_, _, err = textLookup.EncodeResult([]byte("example.com"), expires)
+	require.Nil(t, err)
 }